@@ -0,0 +1,75 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collector
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/intelsdi-x/snap-plugin-utilities/config"
+
+	"github.com/intelsdi-x/snap-plugin-collector-cinder/types"
+)
+
+const (
+	// defaultLimitsTTL is default time after which cached tenant limits expire
+	defaultLimitsTTL = 5 * time.Minute
+	// defaultTenantsTTL is default time after which cached list of tenants expires
+	defaultTenantsTTL = time.Hour
+)
+
+// cached holds time when cached value was fetched
+type cached struct {
+	fetched time.Time
+}
+
+// expired checks whether cached value is older than ttl or was never fetched
+func (c cached) expired(now time.Time, ttl time.Duration) bool {
+	return c.fetched.IsZero() || now.Sub(c.fetched) >= ttl
+}
+
+// age returns number of seconds elapsed since cached value was fetched
+// It returns -1 if value was never fetched
+func (c cached) age(now time.Time) float64 {
+	if c.fetched.IsZero() {
+		return -1
+	}
+	return now.Sub(c.fetched).Seconds()
+}
+
+// cachedLimits holds tenant limits along with time they were fetched
+type cachedLimits struct {
+	cached
+	types.TenantLimits
+}
+
+// getTTL reads cache ttl given as duration string (e.g. "5m") from config
+// It returns default value if item is not provided
+func getTTL(cfg interface{}, item string, def time.Duration) (time.Duration, error) {
+	value, err := config.GetConfigItem(cfg, item)
+	if err != nil {
+		return def, nil
+	}
+
+	ttl, err := time.ParseDuration(fmt.Sprint(value))
+	if err != nil {
+		return 0, fmt.Errorf("Incorrect value of %s: %v", item, err)
+	}
+	if ttl < 0 {
+		return 0, fmt.Errorf("Incorrect value of %s: negative duration %s", item, ttl)
+	}
+
+	return ttl, nil
+}
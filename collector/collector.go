@@ -26,7 +26,6 @@ import (
 	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
 	"github.com/intelsdi-x/snap/core"
 
-	"github.com/intelsdi-x/snap-plugin-utilities/config"
 	"github.com/intelsdi-x/snap-plugin-utilities/ns"
 	"github.com/intelsdi-x/snap-plugin-utilities/str"
 
@@ -41,17 +40,38 @@ const (
 	plgtype = plugin.CollectorPluginType
 	vendor  = "intel"
 	fs      = "openstack"
+
+	// volumeTypes is namespace element for metrics aggregated per volume type
+	volumeTypes = "volume_types"
+	// pools is namespace element for backend pools capacity metrics
+	pools = "pools"
+	// cinderServices is namespace element for cinder services health metrics
+	cinderServices = "services"
+	// cache is namespace element for cache age metrics
+	cache = "cache"
+	// regionElement is namespace element followed by region name when several regions are collected in one task
+	regionElement = "region"
+
+	// names of resources collected by separate API calls
+	limitsResource    = "limits"
+	volumesResource   = "volumes"
+	snapshotsResource = "snapshots"
+	backupsResource   = "backups"
+	poolsResource     = "pools"
+	servicesResource  = "services"
 )
 
 // New creates initialized instance of Cinder collector
 func New() *collector {
 	providers := map[string]*gophercloud.ProviderClient{}
 	allTenants := map[string]string{}
-	allLimits := map[string]types.Limits{}
+	regions := map[string]*cinderRegion{}
+	orphanedOwners := map[string]bool{}
 	return &collector{
-		allTenants: allTenants,
-		providers:  providers,
-		allLimits:  allLimits,
+		allTenants:     allTenants,
+		providers:      providers,
+		regions:        regions,
+		orphanedOwners: orphanedOwners,
 	}
 }
 
@@ -60,25 +80,65 @@ func New() *collector {
 func (c *collector) GetMetricTypes(cfg plugin.ConfigType) ([]plugin.MetricType, error) {
 	mts := []plugin.MetricType{}
 
-	var err error
-	c.allTenants, err = getTenants(cfg)
+	// config and cloud settings are loaded once and shared by all items read below
+	s, err := loadSettings(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	c.allTenants, err = getTenants(s)
 	if err != nil {
 		return nil, err
 	}
+	c.tenantsCache = cached{fetched: time.Now()}
+
+	// Resources of unknown or deleted tenants are reported under orphaned tenant
+	tenantNames := []string{}
+	for _, tenantName := range c.allTenants {
+		tenantNames = append(tenantNames, tenantElement(tenantName))
+	}
+	tenantNames = append(tenantNames, types.OrphanedTenant)
 
 	// Generate available namespace for limits
 	namespaces := []string{}
-	for _, tenantName := range c.allTenants {
-		// Construct temporary struct to generate namespace based on tags
-		var metrics struct {
-			S types.Snapshots `json:"snapshots"`
-			V types.Volumes   `json:"volumes"`
-			L types.Limits    `json:"limits"`
-		}
+	for _, tenantName := range tenantNames {
 		current := strings.Join([]string{vendor, fs, name, tenantName}, "/")
-		ns.FromCompositionTags(metrics, current, &namespaces)
+		if tenantName == types.OrphanedTenant {
+			// Limits are not available for orphaned tenant
+			var metrics struct {
+				S types.Snapshots `json:"snapshots"`
+				V types.Volumes   `json:"volumes"`
+				B types.Backups   `json:"backups"`
+			}
+			ns.FromCompositionTags(metrics, current, &namespaces)
+		} else {
+			// Construct temporary struct to generate namespace based on tags
+			var metrics struct {
+				S types.Snapshots        `json:"snapshots"`
+				V types.Volumes          `json:"volumes"`
+				B types.Backups          `json:"backups"`
+				L types.Limits           `json:"limits"`
+				Q types.QuotaUtilization `json:"quota_utilization"`
+			}
+			ns.FromCompositionTags(metrics, current, &namespaces)
+			namespaces = append(namespaces, strings.Join([]string{current, cache, "limits", "age"}, "/"))
+		}
+		namespaces = append(namespaces, strings.Join([]string{current, "backups", "newest_available_age"}, "/"))
+
+		// Generate namespaces for volumes, snapshots and backups broken down by status, unknown statuses are counted as other
+		for _, status := range append([]string{types.OtherStatus}, types.VolumeStatuses...) {
+			ns.FromCompositionTags(types.Volumes{}, strings.Join([]string{current, "volumes", "status", status}, "/"), &namespaces)
+		}
+		for _, status := range append([]string{types.OtherStatus}, types.SnapshotStatuses...) {
+			ns.FromCompositionTags(types.Snapshots{}, strings.Join([]string{current, "snapshots", "status", status}, "/"), &namespaces)
+		}
+		for _, status := range append([]string{types.OtherStatus}, types.BackupStatuses...) {
+			ns.FromCompositionTags(types.Backups{}, strings.Join([]string{current, "backups", "status", status}, "/"), &namespaces)
+		}
 	}
 
+	namespaces = append(namespaces, strings.Join([]string{vendor, fs, name, cache, "tenants", "age"}, "/"))
+
 	for _, namespace := range namespaces {
 		mts = append(mts, plugin.MetricType{
 			Namespace_: core.NewNamespace(strings.Split(namespace, "/")...),
@@ -86,168 +146,523 @@ func (c *collector) GetMetricTypes(cfg plugin.ConfigType) ([]plugin.MetricType,
 		})
 	}
 
+	// Generate namespaces for volumes per volume type, for each tenant and cloud-wide
+	scopes := append([]string{volumeTypes}, tenantNames...)
+	for _, scope := range scopes {
+		for _, metric := range []string{"count", "bytes"} {
+			namespace := core.NewNamespace(vendor, fs, name, scope)
+			if scope != volumeTypes {
+				namespace = namespace.AddStaticElement(volumeTypes)
+			}
+			mts = append(mts, plugin.MetricType{
+				Namespace_: namespace.AddDynamicElement("volume_type", "Name of volume type").AddStaticElement(metric),
+				Config_:    cfg.ConfigDataNode,
+			})
+		}
+	}
+
+	// Generate namespaces for tenant limits per volume type, not available for orphaned tenant
+	limitNamespaces := []string{}
+	ns.FromCompositionTags(types.VolumeTypeLimits{}, "limits", &limitNamespaces)
+	for _, tenantName := range tenantNames {
+		if tenantName == types.OrphanedTenant {
+			continue
+		}
+		for _, limitNamespace := range limitNamespaces {
+			metric := limitNamespace[strings.LastIndex(limitNamespace, "/")+1:]
+			mts = append(mts, plugin.MetricType{
+				Namespace_: core.NewNamespace(vendor, fs, name, tenantName, "limits", volumeTypes).
+					AddDynamicElement("volume_type", "Name of volume type").
+					AddStaticElement(metric),
+				Config_: cfg.ConfigDataNode,
+			})
+		}
+	}
+
+	// Generate namespaces for backend pools capacity
+	poolNamespaces := []string{}
+	ns.FromCompositionTags(types.Pool{}, poolsResource, &poolNamespaces)
+	for _, poolNamespace := range poolNamespaces {
+		metric := poolNamespace[strings.LastIndex(poolNamespace, "/")+1:]
+		mts = append(mts, plugin.MetricType{
+			Namespace_: core.NewNamespace(vendor, fs, name, pools).AddDynamicElement("pool", "Name of backend pool").AddStaticElement(metric),
+			Config_:    cfg.ConfigDataNode,
+		})
+	}
+
+	// Generate namespaces for cinder services health
+	serviceNamespaces := []string{}
+	ns.FromCompositionTags(types.CinderService{}, servicesResource, &serviceNamespaces)
+	for _, serviceNamespace := range serviceNamespaces {
+		metric := serviceNamespace[strings.LastIndex(serviceNamespace, "/")+1:]
+		mts = append(mts, plugin.MetricType{
+			Namespace_: core.NewNamespace(vendor, fs, name, cinderServices).
+				AddDynamicElement("binary", "Name of cinder service binary").
+				AddDynamicElement("host", "Host running cinder service").
+				AddStaticElement(metric),
+			Config_: cfg.ConfigDataNode,
+		})
+	}
+
+	// Namespaces are prefixed with region when several regions are collected in one task
+	regions, namespaced, err := getRegions(s)
+	if err != nil {
+		return nil, err
+	}
+	if namespaced {
+		regionMts := []plugin.MetricType{}
+		for _, regionName := range regions {
+			for _, mt := range mts {
+				regionMts = append(regionMts, plugin.MetricType{
+					Namespace_: addRegion(mt.Namespace(), regionName),
+					Config_:    mt.Config_,
+				})
+			}
+		}
+		mts = regionMts
+	}
+
 	return mts, nil
 }
 
 // CollectMetrics returns list of requested metric values
 // It returns error in case retrieval was not successful
 func (c *collector) CollectMetrics(metricTypes []plugin.MetricType) ([]plugin.MetricType, error) {
+	// config and cloud settings are loaded once per collection and shared by all items read below
+	s, err := loadSettings(metricTypes[0])
+	if err != nil {
+		return nil, err
+	}
+
 	// get admin tenant from configuration. admin tenant is needed for gathering volumes and snapshots metrics at once
-	item, err := config.GetConfigItem(metricTypes[0], "tenant")
+	admin, err := getStringItem(s, "tenant", true)
 	if err != nil {
 		return nil, err
 	}
-	admin := item.(string)
 
-	// populate information about all available tenants
-	if len(c.allTenants) == 0 {
-		c.allTenants, err = getTenants(metricTypes[0])
+	limitsTTL, err := getTTL(metricTypes[0], "limits_ttl", defaultLimitsTTL)
+	if err != nil {
+		return nil, err
+	}
+	tenantsTTL, err := getTTL(metricTypes[0], "tenants_ttl", defaultTenantsTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	// populate information about all available tenants, refresh it when cached list expires
+	now := time.Now()
+	tenantsRefreshed := false
+	if len(c.allTenants) == 0 || c.tenantsCache.expired(now, tenantsTTL) {
+		allTenants, err := getTenants(s)
 		if err != nil {
 			return nil, err
 		}
+		c.allTenants = allTenants
+		c.tenantsCache = cached{fetched: now}
+		tenantsRefreshed = true
 	}
 
-	// iterate over metric types to resolve needed collection calls
-	// for requested tenants
-	collectTenants := str.InitSet()
-	var collectLimits, collectVolumes, collectSnapshots bool
+	// partial metrics are reported for successfully collected resources when enabled
+	partial, err := getBoolItem(s, "partial_metrics", false)
+	if err != nil {
+		return nil, err
+	}
+
+	// metrics are collected from Cinder endpoints of configured regions and interface
+	regions, namespaced, err := getRegions(s)
+	if err != nil {
+		return nil, err
+	}
+	availability, err := getAvailability(s)
+	if err != nil {
+		return nil, err
+	}
+
+	// group metric types by region, region elements are removed from namespaces so that all regions are handled alike
+	order := []string{}
+	regionMetricTypes := map[string][]plugin.MetricType{}
 	for _, metricType := range metricTypes {
+		regionName := regions[0]
 		namespace := metricType.Namespace()
+		if namespaced {
+			if len(namespace) < 5 || namespace[3].Value != regionElement {
+				return nil, fmt.Errorf("Incorrect namespace %s: expected region element", namespace.String())
+			}
+			regionName, namespace = namespace[4].Value, removeRegion(namespace)
+		}
 		if len(namespace) < 6 {
 			return nil, fmt.Errorf("Incorrect namespace lenth. Expected 6 is %d", len(namespace))
 		}
 
-		tenant := namespace[3].Value
-		collectTenants.Add(tenant)
+		if _, found := regionMetricTypes[regionName]; !found {
+			order = append(order, regionName)
+		}
+		regionMetricTypes[regionName] = append(regionMetricTypes[regionName], plugin.MetricType{
+			Namespace_: namespace,
+			Config_:    metricType.Config_,
+		})
+	}
 
-		if str.Contains(namespace.Strings(), "limits") {
-			collectLimits = true
-		} else if str.Contains(namespace.Strings(), "volumes") {
-			collectVolumes = true
-		} else {
-			collectSnapshots = true
+	// collect resources in each region, errors of all regions are reported together
+	results := map[string]*collection{}
+	errs := MultiError{}
+	for _, regionName := range order {
+		r := c.region(regionName, availability)
+		results[regionName] = c.collectRegion(s, r, regionMetricTypes[regionName], admin, limitsTTL, now, &tenantsRefreshed)
+		errs = append(errs, results[regionName].errors...)
+	}
+
+	// report all collection errors unless partial metrics are requested
+	if len(errs) > 0 && !partial {
+		return nil, errs
+	}
+
+	metrics := []plugin.MetricType{}
+	for _, regionName := range order {
+		for _, metric := range c.regionMetrics(c.regions[regionName], regionMetricTypes[regionName], results[regionName]) {
+			if namespaced {
+				metric.Namespace_ = addRegion(metric.Namespace(), regionName)
+			}
+			metrics = append(metrics, metric)
 		}
 	}
 
-	allSnapshots := map[string]types.Snapshots{}
-	allVolumes := map[string]types.Volumes{}
+	return metrics, nil
+}
 
-	// collect volumes and snapshots separately by authenticating to admin
-	{
-		if err := c.authenticate(metricTypes[0], admin); err != nil {
-			return nil, err
+// collectRegion collects resources needed for given metric types from Cinder endpoints of single region
+// List of tenants is refreshed when resources of unknown tenant are found, unless it was already refreshed during collection
+func (c *collector) collectRegion(s settings, r *cinderRegion, metricTypes []plugin.MetricType, admin string, limitsTTL time.Duration, now time.Time, tenantsRefreshed *bool) *collection {
+	// iterate over metric types to resolve needed collection calls
+	// for requested tenants
+	collectTenants := str.InitSet()
+	collectResources := map[string]bool{}
+	for _, metricType := range metricTypes {
+		tenant, resource := metricResource(metricType.Namespace().Strings())
+		if tenant != "" {
+			collectTenants.Add(tenant)
 		}
-		provider := c.providers[admin]
+		collectResources[resource] = true
+	}
 
+	// results of concurrent calls are gathered in guarded collection
+	results := newCollection(r.name)
+
+	// collect volumes and snapshots separately by authenticating to admin
+	adminProvider, err := c.connect(s, r, admin)
+	if err != nil {
+		for _, resource := range []string{volumesResource, snapshotsResource, backupsResource, poolsResource, servicesResource} {
+			if collectResources[resource] {
+				results.fail("", resource, err)
+			}
+		}
+	} else {
 		var done sync.WaitGroup
-		errChn := make(chan error, 2)
 
 		// Collect volumes
-		if collectVolumes {
+		if collectResources[volumesResource] {
 			done.Add(1)
 			go func() {
 				defer done.Done()
-				volumes, err := c.service.GetVolumes(provider)
+				volumes, err := r.service.GetVolumes(adminProvider)
+				if err != nil {
+					results.fail("", volumesResource, err)
+					return
+				}
 
+				results.Lock()
+				defer results.Unlock()
+				results.volumes = volumes
+				results.volumeTypes = types.SumVolumeTypes(volumes)
+			}()
+		}
+		// Collect snapshots
+		if collectResources[snapshotsResource] {
+			done.Add(1)
+			go func() {
+				defer done.Done()
+				snapshots, err := r.service.GetSnapshots(adminProvider)
 				if err != nil {
-					errChn <- err
+					results.fail("", snapshotsResource, err)
+					return
 				}
-				for tenantId, volumeCount := range volumes {
-					tenantName := c.allTenants[tenantId]
-					allVolumes[tenantName] = volumeCount
+
+				results.Lock()
+				defer results.Unlock()
+				results.snapshots = snapshots
+			}()
+		}
+		// Collect backups
+		if collectResources[backupsResource] {
+			done.Add(1)
+			go func() {
+				defer done.Done()
+				backups, err := r.service.GetBackups(adminProvider)
+				if err != nil {
+					results.fail("", backupsResource, err)
+					return
 				}
+
+				results.Lock()
+				defer results.Unlock()
+				results.backups = backups
 			}()
 		}
-		// Collect snapshots
-		if collectSnapshots {
+		// Collect backend pools, not available in all API versions
+		if collectResources[poolsResource] {
+			done.Add(1)
+			go func() {
+				defer done.Done()
+				backendPools, err := r.service.GetPools(adminProvider)
+				if err == openstackintel.ErrNotSupported {
+					return
+				}
+				if err != nil {
+					results.fail("", poolsResource, err)
+					return
+				}
+
+				results.Lock()
+				defer results.Unlock()
+				for pool, capacity := range backendPools {
+					results.pools[types.SanitizeName(pool)] = capacity
+				}
+			}()
+		}
+		// Collect cinder services health
+		if collectResources[servicesResource] {
 			done.Add(1)
 			go func() {
 				defer done.Done()
-				snapshots, err := c.service.GetSnapshots(provider)
+				services, err := r.service.GetServices(adminProvider)
 				if err != nil {
-					errChn <- err
+					results.fail("", servicesResource, err)
+					return
 				}
 
-				for tenantId, snapshotCount := range snapshots {
-					tenantName := c.allTenants[tenantId]
-					allSnapshots[tenantName] = snapshotCount
+				results.Lock()
+				defer results.Unlock()
+				for binary, hosts := range services {
+					results.services[binary] = map[string]types.CinderService{}
+					for host, health := range hosts {
+						results.services[binary][types.SanitizeName(host)] = health
+					}
 				}
 			}()
 		}
 
 		done.Wait()
-		close(errChn)
 
-		if e := <-errChn; e != nil {
-			return nil, e
+		// resources owned by tenants missing in cached list trigger its refresh, unless their owners
+		// were already missing in refreshed list (e.g. resources left behind by deleted tenant)
+		// Failed refresh is skipped, cached list is still used and refresh is retried on next collection
+		if !*tenantsRefreshed && len(results.unknownTenants(c.allTenants, c.orphanedOwners)) > 0 {
+			if allTenants, err := getTenants(s); err == nil {
+				c.allTenants = allTenants
+				c.tenantsCache = cached{fetched: time.Now()}
+				*tenantsRefreshed = true
+			}
 		}
+		if *tenantsRefreshed {
+			for _, id := range results.unknownTenants(c.allTenants, nil) {
+				c.orphanedOwners[id] = true
+			}
+		}
+		results.resolveTenants(c.allTenants)
 	}
 
-	// Collect limits per each tenant only if not already collected (plugin lifetime scope)
-	{
-		var done sync.WaitGroup
-		errChn := make(chan error, collectTenants.Size())
+	// Collect limits per each tenant only if cached limits expired
+	if collectResources[limitsResource] {
+		tenantIDs := map[string]string{}
+		for tenantID, tenantName := range c.allTenants {
+			tenantIDs[tenantName] = tenantID
+		}
 
+		// Read quotas of all tenants using admin token
+		var done sync.WaitGroup
+		adminFound := adminProvider != nil
 		for _, tenant := range collectTenants.Elements() {
-			_, found := c.allLimits[tenant]
-			if collectLimits && !found {
-				if err := c.authenticate(metricTypes[0], tenant); err != nil {
-					return nil, err
+			if limits, found := r.allLimits[tenant]; found && !limits.expired(now, limitsTTL) {
+				continue
+			}
+
+			tenantID, found := tenantIDs[tenant]
+			if !found || !adminFound {
+				results.limitsFallback = append(results.limitsFallback, tenant)
+				continue
+			}
+
+			done.Add(1)
+			go func(t, id string) {
+				defer done.Done()
+				limits, err := r.service.GetQuotas(adminProvider, id)
+				if err != nil && !openstackintel.IsUnavailable(err) {
+					results.fail(t, limitsResource, err)
+					return
 				}
 
-				provider := c.providers[tenant]
+				results.Lock()
+				defer results.Unlock()
+				if err != nil {
+					results.limitsFallback = append(results.limitsFallback, t)
+					return
+				}
+				results.limits[t] = limits
+			}(tenant, tenantID)
+		}
 
-				done.Add(1)
-				go func(p *gophercloud.ProviderClient, t string) {
-					defer done.Done()
-					limits, err := c.service.GetLimits(p)
-					if err != nil {
-						errChn <- err
-					}
-					c.allLimits[t] = limits
-				}(provider, tenant)
+		done.Wait()
+
+		// Read limits by authenticating to each tenant only when admin API is not available
+		for _, tenant := range results.limitsFallback {
+			provider, err := c.connect(s, r, tenant)
+			if err != nil {
+				results.fail(tenant, limitsResource, err)
+				continue
 			}
+
+			done.Add(1)
+			go func(p *gophercloud.ProviderClient, t string) {
+				defer done.Done()
+				limits, err := r.service.GetLimits(p)
+				if err != nil {
+					results.fail(t, limitsResource, err)
+					return
+				}
+
+				results.Lock()
+				defer results.Unlock()
+				results.limits[t] = limits
+			}(provider, tenant)
 		}
 
 		done.Wait()
-		close(errChn)
 
-		if e := <-errChn; e != nil {
-			return nil, e
+		// limits are cached by collector, store them only when all goroutines are finished
+		for tenant, limits := range results.limits {
+			r.allLimits[tenant] = cachedLimits{cached{fetched: now}, limits}
+		}
+		// limits which failed to be refreshed are dropped to be fetched again on next collection
+		for _, e := range results.errors {
+			if e.Resource == limitsResource {
+				delete(r.allLimits, e.Tenant)
+			}
 		}
 	}
 
+	return results
+}
+
+// regionMetrics returns values of given metric types from resources collected in single region
+func (c *collector) regionMetrics(r *cinderRegion, metricTypes []plugin.MetricType, results *collection) []plugin.MetricType {
 	metrics := []plugin.MetricType{}
 	for _, metricType := range metricTypes {
 		namespace := metricType.Namespace().Strings()
-		tenant := namespace[3]
-		// Construct temporary struct to accommodate all gathered metrics
-		metricContainer := struct {
-			S types.Snapshots `json:"snapshots"`
-			V types.Volumes   `json:"volumes"`
-			L types.Limits    `json:"limits"`
-		}{
-			allSnapshots[tenant],
-			allVolumes[tenant],
-			c.allLimits[tenant],
-		}
-
-		// Extract values by namespace from temporary struct and create metrics
+		element := namespace[3]
+		tenant := tenantName(element)
+
+		// skip metrics of resources which failed to be collected
+		if results.errors.Failed(metricResource(namespace)) {
+			continue
+		}
+
+		if element == pools {
+			metrics = append(metrics, poolMetrics(metricType, results.pools)...)
+			continue
+		} else if element == cinderServices {
+			metrics = append(metrics, serviceMetrics(metricType, results.services)...)
+			continue
+		}
+
+		// Volume type metrics may be requested for all volume types at once
+		if element == volumeTypes {
+			metrics = append(metrics, volumeTypeMetrics(metricType, 4, results.volumeTypes)...)
+			continue
+		} else if namespace[4] == volumeTypes {
+			metrics = append(metrics, volumeTypeMetrics(metricType, 5, results.volumes[tenant].Types)...)
+			continue
+		} else if namespace[4] == "limits" && namespace[5] == volumeTypes {
+			metrics = append(metrics, volumeTypeLimitsMetrics(metricType, r.allLimits[tenant].Types)...)
+			continue
+		}
+
+		// Reserved quota values are not known when limits are not read from quota sets
+		if namespace[4] == "limits" && strings.HasSuffix(namespace[5], "Reserved") && !r.allLimits[tenant].Reserved {
+			continue
+		}
+
+		var data interface{}
+		switch {
+		case namespace[4] == "quota_utilization":
+			// Quota utilization is derived from limits, unlimited quotas are not reported
+			utilization, ok := quotaUtilization(r.allLimits[tenant].Limits, namespace[5])
+			if !ok {
+				continue
+			}
+			data = utilization
+
+		case element == cache:
+			// Age of cached list of tenants
+			data = c.tenantsCache.age(time.Now())
+
+		case namespace[4] == cache:
+			// Age of cached tenant limits, -1 if limits are not cached
+			data = r.allLimits[tenant].age(time.Now())
+
+		case namespace[4] == "backups" && namespace[5] == "newest_available_age":
+			// Age is calculated at collection time, tenants without successful backups are reported as -1
+			data = results.backups[tenant].NewestAvailableAge(time.Now())
+
+		case len(namespace) == 8 && namespace[5] == "status":
+			// Extract values by namespace from status breakdown, statuses without any resources are reported as 0
+			status := namespace[6]
+			switch namespace[4] {
+			case "volumes":
+				data = ns.GetValueByNamespace(results.volumes[tenant].Status[status], namespace[7:])
+			case "backups":
+				data = ns.GetValueByNamespace(results.backups[tenant].Status[status], namespace[7:])
+			default:
+				data = ns.GetValueByNamespace(results.snapshots[tenant].Status[status], namespace[7:])
+			}
+
+		default:
+			// Construct temporary struct to accommodate all gathered metrics
+			metricContainer := struct {
+				S types.Snapshots `json:"snapshots"`
+				V types.Volumes   `json:"volumes"`
+				B types.Backups   `json:"backups"`
+				L types.Limits    `json:"limits"`
+			}{
+				results.snapshots[tenant].Snapshots,
+				results.volumes[tenant].Volumes,
+				results.backups[tenant].Backups,
+				r.allLimits[tenant].Limits,
+			}
+
+			// Extract values by namespace from temporary struct
+			data = ns.GetValueByNamespace(metricContainer, namespace[4:])
+		}
+
 		metric := plugin.MetricType{
 			Timestamp_: time.Now(),
 			Namespace_: metricType.Namespace(),
-			Data_:      ns.GetValueByNamespace(metricContainer, namespace[4:]),
+			Data_:      data,
 		}
 		metrics = append(metrics, metric)
 	}
 
-	return metrics, nil
+	return metrics
 }
 
 // GetConfigPolicy returns config policy
 // It returns error in case retrieval was not successful
 func (c *collector) GetConfigPolicy() (*cpolicy.ConfigPolicy, error) {
 	cp := cpolicy.New()
+	policy, err := configPolicy()
+	if err != nil {
+		return nil, err
+	}
+	cp.Add([]string{vendor, fs, name}, policy)
 	return cp, nil
 }
 
@@ -263,74 +678,417 @@ func Meta() *plugin.PluginMeta {
 	)
 }
 
+// collection gathers results of API calls made concurrently during single collection
+// Volumes, snapshots and backups are keyed by tenant ID until tenants are resolved
+type collection struct {
+	sync.Mutex
+	snapshots   map[string]types.TenantSnapshots
+	volumes     map[string]types.TenantVolumes
+	volumeTypes map[string]types.Volumes
+	backups     map[string]types.TenantBackups
+	pools       map[string]types.Pool
+	services    map[string]map[string]types.CinderService
+	limits      map[string]types.TenantLimits
+	region      string
+	errors      MultiError
+	// limitsFallback lists tenants which limits cannot be read using admin API
+	limitsFallback []string
+}
+
+// unknownTenants returns IDs of owners of collected resources which are missing in given tenants and are not ignored
+// Resources without owner are not taken into account
+func (c *collection) unknownTenants(tenants map[string]string, ignored map[string]bool) []string {
+	c.Lock()
+	defer c.Unlock()
+
+	ids := []string{}
+	for id := range c.volumes {
+		ids = append(ids, id)
+	}
+	for id := range c.snapshots {
+		ids = append(ids, id)
+	}
+	for id := range c.backups {
+		ids = append(ids, id)
+	}
+
+	unknown := []string{}
+	for _, id := range ids {
+		if _, found := tenants[id]; id != "" && !found && !ignored[id] {
+			unknown = append(unknown, id)
+		}
+	}
+	return unknown
+}
+
+// resolveTenants replaces tenant IDs of collected resources with tenant names
+// Resources of unknown tenants are merged into orphaned tenant
+func (c *collection) resolveTenants(tenants map[string]string) {
+	c.Lock()
+	defer c.Unlock()
+
+	name := func(id string) string {
+		if tenantName, found := tenants[id]; found {
+			return tenantName
+		}
+		return types.OrphanedTenant
+	}
+
+	volumes := map[string]types.TenantVolumes{}
+	for id, tenantVolumes := range c.volumes {
+		merged := volumes[name(id)]
+		merged.Merge(tenantVolumes)
+		volumes[name(id)] = merged
+	}
+	c.volumes = volumes
+
+	snapshots := map[string]types.TenantSnapshots{}
+	for id, tenantSnapshots := range c.snapshots {
+		merged := snapshots[name(id)]
+		merged.Merge(tenantSnapshots)
+		snapshots[name(id)] = merged
+	}
+	c.snapshots = snapshots
+
+	backups := map[string]types.TenantBackups{}
+	for id, tenantBackups := range c.backups {
+		merged := backups[name(id)]
+		merged.Merge(tenantBackups)
+		backups[name(id)] = merged
+	}
+	c.backups = backups
+}
+
+// fail records failed collection of resource
+func (c *collection) fail(tenant, resource string, err error) {
+	c.Lock()
+	defer c.Unlock()
+	c.errors = append(c.errors, CollectionError{Region: c.region, Tenant: tenant, Resource: resource, Err: err})
+}
+
+func newCollection(region string) *collection {
+	return &collection{
+		region:      region,
+		snapshots:   map[string]types.TenantSnapshots{},
+		volumes:     map[string]types.TenantVolumes{},
+		volumeTypes: map[string]types.Volumes{},
+		backups:     map[string]types.TenantBackups{},
+		pools:       map[string]types.Pool{},
+		services:    map[string]map[string]types.CinderService{},
+		limits:      map[string]types.TenantLimits{},
+	}
+}
+
+// collector holds state of Cinder collector kept between collections
+// orphanedOwners holds IDs of resource owners missing in refreshed list of tenants, they do not trigger its refresh again
 type collector struct {
-	allTenants map[string]string
+	allTenants     map[string]string
+	tenantsCache   cached
+	common         openstackintel.Commoner
+	providers      map[string]*gophercloud.ProviderClient
+	regions        map[string]*cinderRegion
+	orphanedOwners map[string]bool
+}
+
+// cinderRegion holds Cinder API dispatcher and cached tenant limits of single region
+// Empty name selects Cinder endpoints regardless of region
+type cinderRegion struct {
+	name       string
+	endpoint   gophercloud.EndpointOpts
 	service    services.Service
-	common     openstackintel.Commoner
-	allLimits  map[string]types.Limits
-	providers  map[string]*gophercloud.ProviderClient
+	dispatched bool
+	allLimits  map[string]cachedLimits
 }
 
-func (c *collector) authenticate(cfg interface{}, tenant string) error {
-	if _, found := c.providers[tenant]; !found {
-		domain_name := ""
-		domain_id := ""
-		// get credentials and endpoint from configuration
-		items, err := config.GetConfigItems(cfg, "endpoint", "user", "password")
-		if err != nil {
-			return err
+// region returns state of given region, it is created on first use
+func (c *collector) region(name string, availability gophercloud.Availability) *cinderRegion {
+	if r, found := c.regions[name]; found {
+		return r
+	}
+
+	r := &cinderRegion{
+		name:      name,
+		endpoint:  gophercloud.EndpointOpts{Region: name, Availability: availability},
+		allLimits: map[string]cachedLimits{},
+	}
+	c.regions[name] = r
+	return r
+}
+
+// authenticate creates provider authenticated for given tenant, provider is reused by following collections
+func (c *collector) authenticate(s settings, tenant string) (*gophercloud.ProviderClient, error) {
+	if provider, found := c.providers[tenant]; found {
+		return provider, nil
+	}
+
+	// get credentials and endpoint from configuration
+	creds, err := getCredentials(s)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := openstackintel.Authenticate(creds, tenant)
+	if err != nil {
+		return nil, err
+	}
+	c.providers[tenant] = provider
+
+	// set Commoner interface
+	c.common = openstackintel.Common{}
+
+	return provider, nil
+}
+
+// connect authenticates for given tenant and dispatches Cinder API version of given region
+// Dispatcher is stored only if dispatch succeeded, so that failed dispatch is retried on next collection
+func (c *collector) connect(s settings, r *cinderRegion, tenant string) (*gophercloud.ProviderClient, error) {
+	provider, err := c.authenticate(s, tenant)
+	if err != nil {
+		return nil, err
+	}
+	if r.dispatched {
+		return provider, nil
+	}
+
+	pageSize, err := getPageSize(s.cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// dispatch API version based on priority
+	service, err := services.Dispatch(provider, services.Options{PageSize: pageSize, Endpoint: r.endpoint})
+	if err != nil {
+		return nil, err
+	}
+	r.service = service
+	r.dispatched = true
+
+	return provider, nil
+}
+
+// volumeTypeMetrics returns metrics for volume type found at given position of metric namespace
+// Dynamic volume type element is expanded to all available volume types
+func volumeTypeMetrics(metricType plugin.MetricType, position int, volumes map[string]types.Volumes) []plugin.MetricType {
+	metrics := []plugin.MetricType{}
+
+	available := []string{}
+	for volumeType := range volumes {
+		available = append(available, volumeType)
+	}
+
+	for _, namespace := range expandNamespace(metricType.Namespace(), position, available) {
+		metrics = append(metrics, plugin.MetricType{
+			Timestamp_: time.Now(),
+			Namespace_: namespace,
+			Data_:      ns.GetValueByNamespace(volumes[namespace[position].Value], namespace.Strings()[position+1:]),
+		})
+	}
+
+	return metrics
+}
+
+// volumeTypeLimitsMetrics returns tenant limits metrics for volume type found at position 6 of metric namespace
+// Dynamic volume type element is expanded to all volume types with separate quotas, other volume types are skipped
+func volumeTypeLimitsMetrics(metricType plugin.MetricType, limits map[string]types.VolumeTypeLimits) []plugin.MetricType {
+	metrics := []plugin.MetricType{}
+
+	available := []string{}
+	for volumeType := range limits {
+		available = append(available, volumeType)
+	}
+
+	for _, namespace := range expandNamespace(metricType.Namespace(), 6, available) {
+		typeLimits, found := limits[namespace[6].Value]
+		if !found {
+			continue
 		}
+		metrics = append(metrics, plugin.MetricType{
+			Timestamp_: time.Now(),
+			Namespace_: namespace,
+			Data_:      ns.GetValueByNamespace(typeLimits, namespace.Strings()[7:]),
+		})
+	}
 
-		endpoint := items["endpoint"].(string)
-		user := items["user"].(string)
-		password := items["password"].(string)
-		dom_name, _ := config.GetConfigItem(cfg, "domain_name")
-		dom_id, _ := config.GetConfigItem(cfg, "domain_id")
-		if dom_name != nil {
-			domain_name = dom_name.(string)
+	return metrics
+}
+
+// poolMetrics returns metrics for backend pool found at position 4 of metric namespace
+// Dynamic pool element is expanded to all available pools, pools not reported by scheduler are skipped
+func poolMetrics(metricType plugin.MetricType, allPools map[string]types.Pool) []plugin.MetricType {
+	metrics := []plugin.MetricType{}
+
+	available := []string{}
+	for pool := range allPools {
+		available = append(available, pool)
+	}
+
+	for _, namespace := range expandNamespace(metricType.Namespace(), 4, available) {
+		pool, found := allPools[namespace[4].Value]
+		if !found {
+			continue
 		}
-		if dom_id != nil {
-			domain_id = dom_id.(string)
+		metrics = append(metrics, plugin.MetricType{
+			Timestamp_: time.Now(),
+			Namespace_: namespace,
+			Data_:      ns.GetValueByNamespace(pool, namespace.Strings()[5:]),
+		})
+	}
+
+	return metrics
+}
+
+// serviceMetrics returns metrics for cinder services selected by binary and host found at positions 4 and 5 of metric namespace
+// Dynamic binary and host elements are expanded to all available services
+func serviceMetrics(metricType plugin.MetricType, allServices map[string]map[string]types.CinderService) []plugin.MetricType {
+	metrics := []plugin.MetricType{}
+
+	binaries := []string{}
+	for binary := range allServices {
+		binaries = append(binaries, binary)
+	}
+
+	for _, binaryNamespace := range expandNamespace(metricType.Namespace(), 4, binaries) {
+		hosts := allServices[binaryNamespace[4].Value]
+
+		available := []string{}
+		for host := range hosts {
+			available = append(available, host)
 		}
 
-		provider, err := openstackintel.Authenticate(endpoint, user, password, tenant, domain_name, domain_id)
-		if err != nil {
-			return err
+		for _, namespace := range expandNamespace(binaryNamespace, 5, available) {
+			health, found := hosts[namespace[5].Value]
+			if !found {
+				continue
+			}
+			metrics = append(metrics, plugin.MetricType{
+				Timestamp_: time.Now(),
+				Namespace_: namespace,
+				Data_:      ns.GetValueByNamespace(health, namespace.Strings()[6:]),
+			})
 		}
-		// set provider and dispatch API version based on priority
-		c.providers[tenant] = provider
-		c.service = services.Dispatch(provider)
+	}
+
+	return metrics
+}
 
-		// set Commoner interface
-		c.common = openstackintel.Common{}
+// expandNamespace returns namespaces with concrete value of element at given position
+// Dynamic element is expanded to all available values
+func expandNamespace(namespace core.Namespace, position int, available []string) []core.Namespace {
+	selected := []string{namespace[position].Value}
+	if namespace[position].Value == "*" {
+		selected = available
 	}
 
-	return nil
+	namespaces := []core.Namespace{}
+	for _, value := range selected {
+		current := make(core.Namespace, len(namespace))
+		copy(current, namespace)
+		current[position].Value = value
+		namespaces = append(namespaces, current)
+	}
+
+	return namespaces
 }
 
-func getTenants(cfg interface{}) (map[string]string, error) {
-	items, err := config.GetConfigItems(cfg, "endpoint", "user", "password")
-	domain_name := ""
-	domain_id := ""
-	if err != nil {
-		return nil, err
+// addRegion returns namespace with region element and region name following plugin prefix
+func addRegion(namespace core.Namespace, name string) core.Namespace {
+	current := make(core.Namespace, 0, len(namespace)+2)
+	current = append(current, namespace[:3]...)
+	current = append(current, core.NamespaceElement{Value: regionElement}, core.NamespaceElement{Value: name})
+	return append(current, namespace[3:]...)
+}
+
+// removeRegion returns namespace without region element and region name
+func removeRegion(namespace core.Namespace) core.Namespace {
+	current := make(core.Namespace, 0, len(namespace)-2)
+	current = append(current, namespace[:3]...)
+	return append(current, namespace[5:]...)
+}
+
+// quotaUtilization calculates percentage of tenant quota in use for given resource
+// It returns false if quota is unlimited or not set
+func quotaUtilization(limits types.Limits, resource string) (float64, bool) {
+	var used, max int
+	switch resource {
+	case "volumes":
+		used, max = limits.TotalVolumesUsed, limits.MaxTotalVolumes
+	case "gigabytes":
+		used, max = limits.TotalGigabytesUsed, limits.MaxTotalVolumeGigabytes
+	case "snapshots":
+		used, max = limits.TotalSnapshotsUsed, limits.MaxTotalSnapshots
+	case "backups":
+		used, max = limits.TotalBackupsUsed, limits.MaxTotalBackups
+	default:
+		return 0, false
+	}
+
+	if max <= 0 {
+		return 0, false
+	}
+
+	return float64(used) / float64(max) * 100, true
+}
+
+// metricResource returns tenant and resource which needs to be collected for metric
+// Tenant is empty for metrics not scoped to single tenant
+func metricResource(namespace []string) (string, string) {
+	switch namespace[3] {
+	case volumeTypes:
+		return "", volumesResource
+	case pools:
+		return "", poolsResource
+	case cinderServices:
+		return "", servicesResource
+	case cache:
+		return "", cache
 	}
 
-	endpoint := items["endpoint"].(string)
-	user := items["user"].(string)
-	password := items["password"].(string)
-	dom_name, _ := config.GetConfigItem(cfg, "domain_name")
-	dom_id, _ := config.GetConfigItem(cfg, "domain_id")
-	if dom_name != nil {
-		domain_name = dom_name.(string)
+	tenant := tenantName(namespace[3])
+	switch namespace[4] {
+	case "limits", "quota_utilization", cache:
+		return tenant, limitsResource
+	case "volumes", volumeTypes:
+		return tenant, volumesResource
+	case "backups":
+		return tenant, backupsResource
+	default:
+		return tenant, snapshotsResource
 	}
-	if dom_id != nil {
-		domain_id = dom_id.(string)
+}
+
+// reservedElements are namespace elements of metrics not scoped to single tenant, they take place of tenant name
+var reservedElements = map[string]bool{volumeTypes: true, pools: true, cinderServices: true, cache: true}
+
+// tenantElement returns namespace element of tenant, tenants named like reserved elements are prefixed with underscore
+// Names already prefixed with underscores get one more so that element can be mapped back to tenant name
+func tenantElement(tenant string) string {
+	if reservedElements[strings.TrimLeft(tenant, "_")] {
+		return "_" + tenant
+	}
+	return tenant
+}
+
+// tenantName returns name of tenant of namespace element, it reverts prefix added by tenantElement
+func tenantName(element string) string {
+	if strings.HasPrefix(element, "_") && reservedElements[strings.TrimLeft(element, "_")] {
+		return element[1:]
+	}
+	return element
+}
+
+func getTenants(s settings) (map[string]string, error) {
+	creds, err := getCredentials(s)
+	if err != nil {
+		return nil, err
+	}
+	admin, err := getStringItem(s, "tenant", true)
+	if err != nil {
+		return nil, err
 	}
 
-	// retrieve list of all available tenants for provided endpoint, user and password
+	// retrieve list of all available tenants for provided endpoint and credentials, token is scoped to admin tenant
 	cmn := openstackintel.Common{}
-	allTenants, err := cmn.GetTenants(endpoint, user, password, domain_name, domain_id)
+	allTenants, err := cmn.GetTenants(creds, admin)
 	if err != nil {
 		return nil, err
 	}
@@ -18,11 +18,18 @@ package collector
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/rackspace/gophercloud"
 	th "github.com/rackspace/gophercloud/testhelper"
 	. "github.com/smartystreets/goconvey/convey"
 	"github.com/stretchr/testify/suite"
@@ -33,6 +40,8 @@ import (
 	"github.com/intelsdi-x/snap/core/ctypes"
 
 	"github.com/intelsdi-x/snap-plugin-utilities/str"
+
+	"github.com/intelsdi-x/snap-plugin-collector-cinder/types"
 )
 
 type CollectorSuite struct {
@@ -47,125 +56,1330 @@ type CollectorSuite struct {
 	Vol1Size, Vol2Size                       int
 	VolMeta                                  string
 	SnapShotSize                             int
+	BackupSize                               int
+	CinderUnavailable                        int32
+	SnapshotsUnavailable, BackupsUnavailable int32
+	LimitsRequests, TenantsRequests          int32
+	OrphanedVolume, TenantsUnavailable       int32
+	QuotaSetsForbidden                       int32
+	MultiRegion, InternalRequests            int32
 	server                                   *httptest.Server
 }
 
-func (s *CollectorSuite) SetupSuite() {
-	// for cinder calls
-	th.SetupHTTP()
-	// for identity calls
-	router := mux.NewRouter()
-	s.server = httptest.NewServer(router)
+func (s *CollectorSuite) SetupSuite() {
+	// for cinder calls
+	th.SetupHTTP()
+	// for identity calls
+	router := mux.NewRouter()
+	s.server = httptest.NewServer(router)
+
+	registerIdentityRoot(s, router)
+	s.V1 = "v1/v1ffff"
+	s.V2 = "v2/v2ffff"
+	s.Token = "2ed210f132564f21b178afb197ee99e3"
+	registerIdentityToken(s, router)
+	s.Tenant1Name = "admin"
+	s.Tenant2Name = "demo"
+	s.Tenant1ID = "admin_id123"
+	s.Tenant2ID = "demo_id123"
+	registerIdentityTenants(s, router)
+
+	registerCinderApi(s)
+	registerCinderLimits(s)
+	registerCinderQuotaSets(s)
+	s.Vol1 = "vol1id_123"
+	s.Vol2 = "vol2id_321"
+	s.Vol1Size = 11
+	s.Vol2Size = 22
+	registerCinderVolumes(s)
+	registerCinderInternal(s)
+	s.SnapShotSize = 5
+	registerCinderSnapshots(s)
+	registerCinderPools(s)
+	registerCinderServices(s)
+	s.BackupSize = 3
+	registerCinderBackups(s)
+}
+
+func (s *CollectorSuite) TearDownSuite() {
+	th.TeardownHTTP()
+	s.server.Close()
+}
+
+func (s *CollectorSuite) TestGetMetricTypes() {
+	Convey("Given config with enpoint, user and password defined", s.T(), func() {
+		cfg := setupCfg(s.server.URL, "me", "secret", "admin")
+
+		Convey("When GetMetricTypes() is called", func() {
+			collector := New()
+			mts, err := collector.GetMetricTypes(cfg)
+
+			Convey("Then no error should be reported", func() {
+				So(err, ShouldBeNil)
+			})
+
+			Convey("and proper metric types are returned", func() {
+				metricNames := []string{}
+				for _, m := range mts {
+					metricNames = append(metricNames, m.Namespace().String())
+
+				}
+
+				So(len(mts), ShouldEqual, 321)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/demo/snapshots/count"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/demo/snapshots/bytes"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/demo/volumes/count"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/demo/volumes/bytes"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/demo/limits/MaxTotalVolumeGigabytes"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/demo/limits/MaxTotalVolumes"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/volumes/count"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/volumes/bytes"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/limits/MaxTotalVolumeGigabytes"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/limits/MaxTotalVolumes"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/snapshots/count"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/snapshots/bytes"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/limits/MaxTotalSnapshots"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/limits/MaxTotalBackups"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/limits/MaxTotalBackupGigabytes"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/limits/TotalVolumesUsed"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/limits/TotalGigabytesUsed"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/limits/TotalSnapshotsUsed"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/limits/TotalBackupsUsed"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/limits/TotalBackupGigabytesUsed"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/quota_utilization/volumes"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/quota_utilization/gigabytes"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/quota_utilization/snapshots"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/quota_utilization/backups"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/volumes/status/available/count"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/volumes/status/error/bytes"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/demo/volumes/status/in-use/count"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/demo/snapshots/status/error_deleting/count"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/demo/snapshots/status/available/bytes"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/demo/snapshots/status/other/count"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/demo/volume_types/*/count"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/volume_types/*/bytes"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/volume_types/*/count"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/volume_types/*/bytes"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/pools/*/total_capacity_gb"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/pools/*/free_capacity_gb"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/pools/*/allocated_capacity_gb"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/pools/*/provisioned_capacity_gb"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/pools/*/max_over_subscription_ratio"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/pools/*/reserved_percentage"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/services/*/*/state"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/services/*/*/status"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/services/*/*/updated_seconds_ago"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/demo/backups/count"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/demo/backups/bytes"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/demo/backups/newest_available_age"), ShouldBeTrue)
+				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/backups/status/error/count"), ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func (s *CollectorSuite) TestCollectMetrics() {
+
+	Convey("Given set of metric types", s.T(), func() {
+		cfg := setupCfg(s.server.URL, "me", "secret", "admin")
+		m1 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "demo", "limits", "MaxTotalVolumeGigabytes"),
+			Config_:    cfg.ConfigDataNode}
+		m2 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "demo", "volumes", "count"),
+			Config_:    cfg.ConfigDataNode}
+		m3 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "demo", "snapshots", "bytes"),
+			Config_:    cfg.ConfigDataNode}
+		m4 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "demo", "quota_utilization", "volumes"),
+			Config_:    cfg.ConfigDataNode}
+		m5 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "demo", "volumes", "status", "available", "count"),
+			Config_:    cfg.ConfigDataNode}
+		m6 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "demo", "volumes", "status", "error", "count"),
+			Config_:    cfg.ConfigDataNode}
+		m7 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "demo", "snapshots", "status", "available", "bytes"),
+			Config_:    cfg.ConfigDataNode}
+		m8 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "demo", "volume_types").
+				AddDynamicElement("volume_type", "Name of volume type").AddStaticElement("count"),
+			Config_: cfg.ConfigDataNode}
+		m9 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "volume_types", "none", "bytes"),
+			Config_:    cfg.ConfigDataNode}
+		m10 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "pools").
+				AddDynamicElement("pool", "Name of backend pool").AddStaticElement("free_capacity_gb"),
+			Config_: cfg.ConfigDataNode}
+		m11 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "services").
+				AddDynamicElement("binary", "Name of cinder service binary").
+				AddDynamicElement("host", "Host running cinder service").
+				AddStaticElement("state"),
+			Config_: cfg.ConfigDataNode}
+		m12 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "admin", "backups", "count"),
+			Config_:    cfg.ConfigDataNode}
+		m13 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "admin", "backups", "status", "available", "bytes"),
+			Config_:    cfg.ConfigDataNode}
+		m14 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "admin", "backups", "newest_available_age"),
+			Config_:    cfg.ConfigDataNode}
+		m15 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "demo", "backups", "newest_available_age"),
+			Config_:    cfg.ConfigDataNode}
+		m16 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "admin", "snapshots", "status", "other", "count"),
+			Config_:    cfg.ConfigDataNode}
+
+		Convey("When ColelctMetrics() is called", func() {
+			collector := New()
+
+			mts, err := collector.CollectMetrics([]plugin.MetricType{m1, m2, m3, m4, m5, m6, m7, m8, m9, m10, m11, m12, m13, m14, m15, m16})
+
+			Convey("Then no error should be reported", func() {
+				So(err, ShouldBeNil)
+			})
+
+			Convey("and proper metric types are returned", func() {
+				metricNames := map[string]interface{}{}
+				for _, m := range mts {
+					ns := m.Namespace().String()
+					metricNames[ns] = m.Data()
+					fmt.Println(ns, "=", m.Data())
+				}
+
+				So(len(mts), ShouldEqual, 17)
+
+				val, ok := metricNames["/intel/openstack/cinder/demo/limits/MaxTotalVolumeGigabytes"]
+				So(ok, ShouldBeTrue)
+				So(val, ShouldEqual, s.MaxTotalVolumeGigabytes)
+
+				val, ok = metricNames["/intel/openstack/cinder/demo/volumes/count"]
+				So(ok, ShouldBeTrue)
+				So(val, ShouldEqual, 1)
+
+				val, ok = metricNames["/intel/openstack/cinder/demo/snapshots/bytes"]
+				So(ok, ShouldBeTrue)
+				So(val, ShouldEqual, s.SnapShotSize*1024*1024*1024)
+
+				val, ok = metricNames["/intel/openstack/cinder/demo/quota_utilization/volumes"]
+				So(ok, ShouldBeTrue)
+				So(val, ShouldEqual, 20.0)
+
+				val, ok = metricNames["/intel/openstack/cinder/demo/volumes/status/available/count"]
+				So(ok, ShouldBeTrue)
+				So(val, ShouldEqual, 1)
+
+				val, ok = metricNames["/intel/openstack/cinder/demo/volumes/status/error/count"]
+				So(ok, ShouldBeTrue)
+				So(val, ShouldEqual, 0)
+
+				val, ok = metricNames["/intel/openstack/cinder/demo/snapshots/status/available/bytes"]
+				So(ok, ShouldBeTrue)
+				So(val, ShouldEqual, s.SnapShotSize*1024*1024*1024)
+
+				val, ok = metricNames["/intel/openstack/cinder/demo/volume_types/none/count"]
+				So(ok, ShouldBeTrue)
+				So(val, ShouldEqual, 1)
+
+				val, ok = metricNames["/intel/openstack/cinder/volume_types/none/bytes"]
+				So(ok, ShouldBeTrue)
+				So(val, ShouldEqual, (s.Vol1Size+s.Vol2Size)*1024*1024*1024)
+
+				val, ok = metricNames["/intel/openstack/cinder/pools/cinder_lvm_lvm/free_capacity_gb"]
+				So(ok, ShouldBeTrue)
+				So(val, ShouldEqual, 60)
+
+				val, ok = metricNames["/intel/openstack/cinder/services/cinder-scheduler/controller/state"]
+				So(ok, ShouldBeTrue)
+				So(val, ShouldEqual, 1)
+
+				val, ok = metricNames["/intel/openstack/cinder/services/cinder-volume/controller_lvm/state"]
+				So(ok, ShouldBeTrue)
+				So(val, ShouldEqual, 0)
+
+				val, ok = metricNames["/intel/openstack/cinder/admin/backups/count"]
+				So(ok, ShouldBeTrue)
+				So(val, ShouldEqual, 2)
+
+				val, ok = metricNames["/intel/openstack/cinder/admin/backups/status/available/bytes"]
+				So(ok, ShouldBeTrue)
+				So(val, ShouldEqual, s.BackupSize*1024*1024*1024)
+
+				val, ok = metricNames["/intel/openstack/cinder/admin/backups/newest_available_age"]
+				So(ok, ShouldBeTrue)
+				So(val, ShouldBeGreaterThan, 0)
+
+				val, ok = metricNames["/intel/openstack/cinder/demo/backups/newest_available_age"]
+				So(ok, ShouldBeTrue)
+				So(val, ShouldEqual, -1)
+
+				val, ok = metricNames["/intel/openstack/cinder/admin/snapshots/status/other/count"]
+				So(ok, ShouldBeTrue)
+				So(val, ShouldEqual, 1)
+			})
+		})
+	})
+}
+
+func (s *CollectorSuite) TestCollectMetricsDispatchRetry() {
+	Convey("Given set of metric types", s.T(), func() {
+		cfg := setupCfg(s.server.URL, "me", "secret", "admin")
+		m := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "demo", "volumes", "count"),
+			Config_:    cfg.ConfigDataNode}
+		collector := New()
+
+		Convey("When Cinder API versions are unavailable", func() {
+			atomic.StoreInt32(&s.CinderUnavailable, 1)
+			_, err := collector.CollectMetrics([]plugin.MetricType{m})
+			atomic.StoreInt32(&s.CinderUnavailable, 0)
+
+			Convey("Then error is reported instead of panic", func() {
+				So(err, ShouldNotBeNil)
+			})
+
+			Convey("and dispatch is retried on next collection", func() {
+				mts, err := collector.CollectMetrics([]plugin.MetricType{m})
+				So(err, ShouldBeNil)
+				So(len(mts), ShouldEqual, 1)
+				So(mts[0].Data(), ShouldEqual, 1)
+			})
+		})
+	})
+}
+
+func (s *CollectorSuite) TestCollectMetricsRegions() {
+	Convey("Given Cinder endpoints in several regions", s.T(), func() {
+		atomic.StoreInt32(&s.MultiRegion, 1)
+		defer atomic.StoreInt32(&s.MultiRegion, 0)
+		cfg := setupCfg(s.server.URL, "me", "secret", "admin")
+		collector := New()
+
+		Convey("When several regions are configured", func() {
+			cfg.AddItem("regions", ctypes.ConfigValueStr{Value: "RegionOne, RegionTwo"})
+
+			Convey("and GetMetricTypes() is called", func() {
+				mts, err := collector.GetMetricTypes(cfg)
+
+				Convey("Then metric types are namespaced by region", func() {
+					So(err, ShouldBeNil)
+					metricNames := []string{}
+					for _, m := range mts {
+						metricNames = append(metricNames, m.Namespace().String())
+					}
+					So(len(mts), ShouldEqual, 2*321)
+					So(str.Contains(metricNames, "/intel/openstack/cinder/region/RegionOne/demo/volumes/count"), ShouldBeTrue)
+					So(str.Contains(metricNames, "/intel/openstack/cinder/region/RegionTwo/pools/*/total_capacity_gb"), ShouldBeTrue)
+					So(str.Contains(metricNames, "/intel/openstack/cinder/demo/volumes/count"), ShouldBeFalse)
+				})
+			})
+
+			Convey("and metrics of each region are collected", func() {
+				m1 := plugin.MetricType{
+					Namespace_: core.NewNamespace("intel", "openstack", "cinder", "region", "RegionOne", "demo", "volumes", "count"),
+					Config_:    cfg.ConfigDataNode}
+				m2 := plugin.MetricType{
+					Namespace_: core.NewNamespace("intel", "openstack", "cinder", "region", "RegionTwo", "demo", "volumes", "count"),
+					Config_:    cfg.ConfigDataNode}
+				m3 := plugin.MetricType{
+					Namespace_: core.NewNamespace("intel", "openstack", "cinder", "region", "RegionTwo", "pools").
+						AddDynamicElement("pool", "").AddStaticElement("total_capacity_gb"),
+					Config_: cfg.ConfigDataNode}
+				mts, err := collector.CollectMetrics([]plugin.MetricType{m1, m2, m3})
+
+				Convey("Then metrics are reported with region in namespace", func() {
+					So(err, ShouldBeNil)
+					So(len(mts), ShouldEqual, 3)
+					So(mts[0].Namespace().String(), ShouldEqual, "/intel/openstack/cinder/region/RegionOne/demo/volumes/count")
+					So(mts[0].Data(), ShouldEqual, 1)
+					So(mts[1].Namespace().String(), ShouldEqual, "/intel/openstack/cinder/region/RegionTwo/demo/volumes/count")
+					So(mts[1].Data(), ShouldEqual, 1)
+					So(mts[2].Namespace().String(), ShouldStartWith, "/intel/openstack/cinder/region/RegionTwo/pools/")
+				})
+			})
+
+			Convey("and metrics of region missing in service catalog are collected", func() {
+				m := plugin.MetricType{
+					Namespace_: core.NewNamespace("intel", "openstack", "cinder", "region", "RegionThree", "demo", "volumes", "count"),
+					Config_:    cfg.ConfigDataNode}
+				_, err := collector.CollectMetrics([]plugin.MetricType{m})
+
+				Convey("Then error mentioning region is reported", func() {
+					So(err, ShouldNotBeNil)
+					So(err.Error(), ShouldContainSubstring, "RegionThree")
+				})
+			})
+		})
+
+		Convey("When single region is configured", func() {
+			cfg.AddItem("region", ctypes.ConfigValueStr{Value: "RegionTwo"})
+			m := plugin.MetricType{
+				Namespace_: core.NewNamespace("intel", "openstack", "cinder", "demo", "volumes", "count"),
+				Config_:    cfg.ConfigDataNode}
+			mts, err := collector.CollectMetrics([]plugin.MetricType{m})
+
+			Convey("Then metrics are collected without region in namespace", func() {
+				So(err, ShouldBeNil)
+				So(len(mts), ShouldEqual, 1)
+				So(mts[0].Namespace().String(), ShouldEqual, "/intel/openstack/cinder/demo/volumes/count")
+				So(mts[0].Data(), ShouldEqual, 1)
+			})
+		})
+
+		Convey("When region is not configured", func() {
+			m := plugin.MetricType{
+				Namespace_: core.NewNamespace("intel", "openstack", "cinder", "demo", "volumes", "count"),
+				Config_:    cfg.ConfigDataNode}
+			_, err := collector.CollectMetrics([]plugin.MetricType{m})
+
+			Convey("Then ambiguous Cinder endpoint is reported", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When internal interface is configured", func() {
+			cfg.AddItem("region", ctypes.ConfigValueStr{Value: "RegionTwo"})
+			cfg.AddItem("interface", ctypes.ConfigValueStr{Value: "internal"})
+			m := plugin.MetricType{
+				Namespace_: core.NewNamespace("intel", "openstack", "cinder", "demo", "volumes", "count"),
+				Config_:    cfg.ConfigDataNode}
+			atomic.StoreInt32(&s.InternalRequests, 0)
+			mts, err := collector.CollectMetrics([]plugin.MetricType{m})
+
+			Convey("Then internal endpoint is used", func() {
+				So(err, ShouldBeNil)
+				So(atomic.LoadInt32(&s.InternalRequests), ShouldBeGreaterThan, 0)
+				So(len(mts), ShouldEqual, 1)
+				So(mts[0].Data(), ShouldEqual, 0)
+			})
+		})
+	})
+}
+
+func (s *CollectorSuite) TestCollectMetricsManyTenants() {
+	Convey("Given metric types requested for many tenants", s.T(), func() {
+		cfg := setupCfg(s.server.URL, "me", "secret", "admin")
+		tenantCount := 50
+		mts := []plugin.MetricType{}
+		for i := 0; i < tenantCount; i++ {
+			tenant := fmt.Sprintf("tenant_%d", i)
+			mts = append(mts,
+				plugin.MetricType{
+					Namespace_: core.NewNamespace("intel", "openstack", "cinder", tenant, "limits", "MaxTotalVolumes"),
+					Config_:    cfg.ConfigDataNode},
+				plugin.MetricType{
+					Namespace_: core.NewNamespace("intel", "openstack", "cinder", tenant, "volumes", "count"),
+					Config_:    cfg.ConfigDataNode},
+				plugin.MetricType{
+					Namespace_: core.NewNamespace("intel", "openstack", "cinder", tenant, "snapshots", "count"),
+					Config_:    cfg.ConfigDataNode},
+				plugin.MetricType{
+					Namespace_: core.NewNamespace("intel", "openstack", "cinder", tenant, "backups", "count"),
+					Config_:    cfg.ConfigDataNode},
+			)
+		}
+		mts = append(mts,
+			plugin.MetricType{
+				Namespace_: core.NewNamespace("intel", "openstack", "cinder", "pools").
+					AddDynamicElement("pool", "").AddStaticElement("free_capacity_gb"),
+				Config_: cfg.ConfigDataNode},
+			plugin.MetricType{
+				Namespace_: core.NewNamespace("intel", "openstack", "cinder", "services").
+					AddDynamicElement("binary", "").AddDynamicElement("host", "").AddStaticElement("state"),
+				Config_: cfg.ConfigDataNode},
+		)
+		collector := New()
+
+		Convey("When CollectMetrics is called", func() {
+			metrics, err := collector.CollectMetrics(mts)
+
+			Convey("Then results of concurrent calls are gathered for all tenants", func() {
+				So(err, ShouldBeNil)
+				So(len(metrics), ShouldEqual, 4*tenantCount+3)
+
+				limits := 0
+				for _, m := range metrics {
+					if m.Namespace()[4].Value == "limits" {
+						So(m.Data(), ShouldEqual, s.MaxTotalVolumes)
+						limits++
+					}
+				}
+				So(limits, ShouldEqual, tenantCount)
+			})
+		})
+	})
+}
+
+func (s *CollectorSuite) TestCollectMetricsErrors() {
+	Convey("Given set of metric types", s.T(), func() {
+		cfg := setupCfg(s.server.URL, "me", "secret", "admin")
+		m1 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "demo", "volumes", "count"),
+			Config_:    cfg.ConfigDataNode}
+		m2 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "demo", "snapshots", "count"),
+			Config_:    cfg.ConfigDataNode}
+		m3 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "demo", "backups", "count"),
+			Config_:    cfg.ConfigDataNode}
+		collector := New()
+
+		atomic.StoreInt32(&s.SnapshotsUnavailable, 1)
+		atomic.StoreInt32(&s.BackupsUnavailable, 1)
+		Reset(func() {
+			atomic.StoreInt32(&s.SnapshotsUnavailable, 0)
+			atomic.StoreInt32(&s.BackupsUnavailable, 0)
+		})
+
+		Convey("When collection of snapshots and backups fails", func() {
+			mts, err := collector.CollectMetrics([]plugin.MetricType{m1, m2, m3})
+
+			Convey("Then all collection errors are reported", func() {
+				So(mts, ShouldBeNil)
+				So(err, ShouldNotBeNil)
+				multiErr, ok := err.(MultiError)
+				So(ok, ShouldBeTrue)
+				So(len(multiErr), ShouldEqual, 2)
+				So(multiErr.Failed("demo", "snapshots"), ShouldBeTrue)
+				So(multiErr.Failed("demo", "backups"), ShouldBeTrue)
+				So(multiErr.Failed("demo", "volumes"), ShouldBeFalse)
+			})
+		})
+
+		Convey("When partial metrics are enabled", func() {
+			cfg.AddItem("partial_metrics", ctypes.ConfigValueBool{Value: true})
+			mts, err := collector.CollectMetrics([]plugin.MetricType{m1, m2, m3})
+
+			Convey("Then metrics of successfully collected resources are returned", func() {
+				So(err, ShouldBeNil)
+				So(len(mts), ShouldEqual, 1)
+				So(mts[0].Namespace().String(), ShouldEqual, "/intel/openstack/cinder/demo/volumes/count")
+				So(mts[0].Data(), ShouldEqual, 1)
+			})
+		})
+	})
+}
+
+func (s *CollectorSuite) TestCollectMetricsLimitsCache() {
+	Convey("Given limits metric types", s.T(), func() {
+		cfg := setupCfg(s.server.URL, "me", "secret", "admin")
+		m1 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "demo", "limits", "MaxTotalVolumes"),
+			Config_:    cfg.ConfigDataNode}
+		m2 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "demo", "cache", "limits", "age"),
+			Config_:    cfg.ConfigDataNode}
+		m3 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "cache", "tenants", "age"),
+			Config_:    cfg.ConfigDataNode}
+		collector := New()
+
+		Convey("When limits are collected twice within ttl", func() {
+			before := atomic.LoadInt32(&s.LimitsRequests)
+			_, err := collector.CollectMetrics([]plugin.MetricType{m1})
+			So(err, ShouldBeNil)
+			mts, err := collector.CollectMetrics([]plugin.MetricType{m1, m2, m3})
+
+			Convey("Then cached limits are reported", func() {
+				So(err, ShouldBeNil)
+				So(atomic.LoadInt32(&s.LimitsRequests)-before, ShouldEqual, 1)
+				So(len(mts), ShouldEqual, 3)
+				So(mts[0].Data(), ShouldEqual, s.MaxTotalVolumes)
+			})
+
+			Convey("and cache age is reported", func() {
+				So(mts[1].Data(), ShouldBeGreaterThanOrEqualTo, 0)
+				So(mts[2].Data(), ShouldBeGreaterThanOrEqualTo, 0)
+			})
+		})
+
+		Convey("When cached limits expire", func() {
+			cfg.AddItem("limits_ttl", ctypes.ConfigValueStr{Value: "0s"})
+			before := atomic.LoadInt32(&s.LimitsRequests)
+			_, err := collector.CollectMetrics([]plugin.MetricType{m1})
+			So(err, ShouldBeNil)
+			mts, err := collector.CollectMetrics([]plugin.MetricType{m1})
+
+			Convey("Then limits are fetched again", func() {
+				So(err, ShouldBeNil)
+				So(atomic.LoadInt32(&s.LimitsRequests)-before, ShouldEqual, 2)
+				So(mts[0].Data(), ShouldEqual, s.MaxTotalVolumes)
+			})
+		})
+
+		Convey("When limits ttl is incorrect", func() {
+			cfg.AddItem("limits_ttl", ctypes.ConfigValueStr{Value: "soon"})
+			_, err := collector.CollectMetrics([]plugin.MetricType{m1})
+
+			Convey("Then error is reported", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+func (s *CollectorSuite) TestCollectMetricsPageSize() {
+	Convey("Given volumes metric type", s.T(), func() {
+		cfg := setupCfg(s.server.URL, "me", "secret", "admin")
+		m := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "demo", "volumes", "count"),
+			Config_:    cfg.ConfigDataNode}
+		collector := New()
+
+		Convey("When page size is configured", func() {
+			cfg.AddItem("page_size", ctypes.ConfigValueInt{Value: 500})
+			mts, err := collector.CollectMetrics([]plugin.MetricType{m})
+
+			Convey("Then volumes are collected", func() {
+				So(err, ShouldBeNil)
+				So(len(mts), ShouldEqual, 1)
+				So(mts[0].Data(), ShouldEqual, 1)
+			})
+		})
+
+		Convey("When page size is negative", func() {
+			cfg.AddItem("page_size", ctypes.ConfigValueInt{Value: -1})
+			_, err := collector.CollectMetrics([]plugin.MetricType{m})
+
+			Convey("Then error is reported", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "page_size")
+			})
+		})
+	})
+}
+
+func (s *CollectorSuite) TestCollectMetricsQuotaSets() {
+	Convey("Given limits metric types", s.T(), func() {
+		cfg := setupCfg(s.server.URL, "me", "secret", "admin")
+		m1 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "demo", "limits", "MaxTotalVolumes"),
+			Config_:    cfg.ConfigDataNode}
+		m2 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "demo", "limits", "TotalVolumesReserved"),
+			Config_:    cfg.ConfigDataNode}
+		m3 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "demo", "limits", "volume_types").
+				AddDynamicElement("volume_type", "").AddStaticElement("max_gigabytes"),
+			Config_: cfg.ConfigDataNode}
+		collector := New()
+
+		Convey("When admin quota sets API is available", func() {
+			mts, err := collector.CollectMetrics([]plugin.MetricType{m1, m2, m3})
+
+			Convey("Then limits with reservations are reported", func() {
+				So(err, ShouldBeNil)
+				So(len(mts), ShouldEqual, 3)
+				So(mts[0].Data(), ShouldEqual, s.MaxTotalVolumes)
+				So(mts[1].Data(), ShouldEqual, 1)
+			})
+
+			Convey("and limits per volume type are reported", func() {
+				So(mts[2].Namespace().String(), ShouldEqual, "/intel/openstack/cinder/demo/limits/volume_types/SSD/max_gigabytes")
+				So(mts[2].Data(), ShouldEqual, 100)
+			})
+
+			Convey("and collector does not authenticate to tenant", func() {
+				_, found := collector.providers["demo"]
+				So(found, ShouldBeFalse)
+			})
+		})
+
+		Convey("When admin quota sets API is not available", func() {
+			atomic.StoreInt32(&s.QuotaSetsForbidden, 1)
+			mts, err := collector.CollectMetrics([]plugin.MetricType{m1, m2})
+			atomic.StoreInt32(&s.QuotaSetsForbidden, 0)
+
+			Convey("Then limits are read by authenticating to tenant", func() {
+				So(err, ShouldBeNil)
+				So(len(mts), ShouldEqual, 1)
+				So(mts[0].Data(), ShouldEqual, s.MaxTotalVolumes)
+				_, found := collector.providers["demo"]
+				So(found, ShouldBeTrue)
+			})
+
+			Convey("and reserved values are not reported", func() {
+				for _, mt := range mts {
+					So(mt.Namespace().String(), ShouldNotEndWith, "Reserved")
+				}
+			})
+		})
+	})
+}
+
+func (s *CollectorSuite) TestCollectMetricsOrphanedTenant() {
+	Convey("Given volumes of deleted tenant", s.T(), func() {
+		cfg := setupCfg(s.server.URL, "me", "secret", "admin")
+		m1 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "demo", "volumes", "count"),
+			Config_:    cfg.ConfigDataNode}
+		m2 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", types.OrphanedTenant, "volumes", "count"),
+			Config_:    cfg.ConfigDataNode}
+		m3 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", types.OrphanedTenant, "volumes", "bytes"),
+			Config_:    cfg.ConfigDataNode}
+		m4 := plugin.MetricType{
+			Namespace_: core.NewNamespace("intel", "openstack", "cinder", types.OrphanedTenant, "volume_types").
+				AddDynamicElement("volume_type", "Name of volume type").AddStaticElement("count"),
+			Config_: cfg.ConfigDataNode}
+		collector := New()
+
+		Reset(func() {
+			atomic.StoreInt32(&s.OrphanedVolume, 0)
+			atomic.StoreInt32(&s.TenantsUnavailable, 0)
+		})
+
+		Convey("When metrics are collected", func() {
+			before := atomic.LoadInt32(&s.TenantsRequests)
+			_, err := collector.CollectMetrics([]plugin.MetricType{m1, m2, m3, m4})
+			So(err, ShouldBeNil)
+			So(atomic.LoadInt32(&s.TenantsRequests)-before, ShouldEqual, 1)
+			atomic.StoreInt32(&s.OrphanedVolume, 1)
+			_, err = collector.CollectMetrics([]plugin.MetricType{m1, m2, m3, m4})
+			So(err, ShouldBeNil)
+			refreshed := atomic.LoadInt32(&s.TenantsRequests) - before
+			mts, err := collector.CollectMetrics([]plugin.MetricType{m1, m2, m3, m4})
+
+			Convey("Then unknown tenant triggers refresh of cached tenants", func() {
+				So(refreshed, ShouldEqual, 2)
+			})
+
+			Convey("and tenant still unknown after refresh does not trigger it again", func() {
+				So(err, ShouldBeNil)
+				So(atomic.LoadInt32(&s.TenantsRequests)-before, ShouldEqual, 2)
+			})
+
+			Convey("and volumes of unknown tenant are reported under orphaned tenant", func() {
+				So(len(mts), ShouldEqual, 4)
+				So(mts[0].Data(), ShouldEqual, 1)
+				So(mts[1].Data(), ShouldEqual, 1)
+				So(mts[2].Data(), ShouldEqual, 7*1024*1024*1024)
+			})
+
+			Convey("and volume type name is sanitized in namespace", func() {
+				So(mts[3].Namespace().String(), ShouldEqual, "/intel/openstack/cinder/_orphaned/volume_types/gold_ha/count")
+				So(mts[3].Data(), ShouldEqual, 1)
+			})
+		})
+
+		Convey("When refresh of cached tenants fails", func() {
+			before := atomic.LoadInt32(&s.TenantsRequests)
+			_, err := collector.CollectMetrics([]plugin.MetricType{m1, m2})
+			So(err, ShouldBeNil)
+			atomic.StoreInt32(&s.OrphanedVolume, 1)
+			atomic.StoreInt32(&s.TenantsUnavailable, 1)
+			mts, err := collector.CollectMetrics([]plugin.MetricType{m1, m2})
+			atomic.StoreInt32(&s.TenantsUnavailable, 0)
+
+			Convey("Then metrics are reported using cached tenants", func() {
+				So(err, ShouldBeNil)
+				So(len(mts), ShouldEqual, 2)
+				So(mts[0].Data(), ShouldEqual, 1)
+				So(mts[1].Data(), ShouldEqual, 1)
+			})
+
+			Convey("and refresh is retried on next collection", func() {
+				_, err := collector.CollectMetrics([]plugin.MetricType{m1, m2})
+				So(err, ShouldBeNil)
+				So(atomic.LoadInt32(&s.TenantsRequests)-before, ShouldEqual, 3)
+			})
+		})
+	})
+}
+
+func TestCached(t *testing.T) {
+	Convey("Given cached value", t, func() {
+		now := time.Now()
+
+		Convey("When value was never fetched", func() {
+			c := cached{}
+
+			Convey("Then it is expired and age is -1", func() {
+				So(c.expired(now, time.Hour), ShouldBeTrue)
+				So(c.age(now), ShouldEqual, -1)
+			})
+		})
+
+		Convey("When value was fetched recently", func() {
+			c := cached{fetched: now.Add(-time.Minute)}
+
+			Convey("Then it expires only after ttl", func() {
+				So(c.expired(now, time.Hour), ShouldBeFalse)
+				So(c.expired(now, time.Minute), ShouldBeTrue)
+				So(c.age(now), ShouldEqual, 60)
+			})
+		})
+	})
+}
+
+func TestGetConfigPolicy(t *testing.T) {
+	Convey("Given config policy of collector", t, func() {
+		cp, err := New().GetConfigPolicy()
+		So(err, ShouldBeNil)
+		policy := cp.Get([]string{vendor, fs, name})
+		So(policy, ShouldNotBeNil)
+
+		Convey("When endpoint and tenant are missing", func() {
+			_, errs := policy.Process(map[string]ctypes.ConfigValue{
+				"cloud": ctypes.ConfigValueStr{Value: "mycloud"},
+			})
+
+			Convey("Then config is accepted, so that they can be read from cloud settings", func() {
+				So(errs.HasErrors(), ShouldBeFalse)
+			})
+		})
+
+		Convey("When item has incorrect type", func() {
+			_, errs := policy.Process(map[string]ctypes.ConfigValue{
+				"endpoint": ctypes.ConfigValueStr{Value: "http://localhost:5000"},
+				"insecure": ctypes.ConfigValueStr{Value: "yes"},
+			})
+
+			Convey("Then config is rejected", func() {
+				So(errs.HasErrors(), ShouldBeTrue)
+			})
+		})
+
+		Convey("When required items are provided", func() {
+			cfg, errs := policy.Process(map[string]ctypes.ConfigValue{
+				"endpoint": ctypes.ConfigValueStr{Value: "http://localhost:5000"},
+				"user":     ctypes.ConfigValueStr{Value: "me"},
+				"password": ctypes.ConfigValueStr{Value: "secret"},
+				"tenant":   ctypes.ConfigValueStr{Value: "admin"},
+			})
+
+			Convey("Then config is accepted with defaults of optional items", func() {
+				So(errs.HasErrors(), ShouldBeFalse)
+				So((*cfg)["limits_ttl"], ShouldResemble, ctypes.ConfigValueStr{Value: "5m0s"})
+				So((*cfg)["tenants_ttl"], ShouldResemble, ctypes.ConfigValueStr{Value: "1h0m0s"})
+				So((*cfg)["partial_metrics"], ShouldResemble, ctypes.ConfigValueBool{Value: false})
+				So((*cfg)["interface"], ShouldBeNil)
+				So((*cfg)["insecure"], ShouldBeNil)
+			})
+		})
+	})
+}
+
+func TestGetCredentials(t *testing.T) {
+	Convey("Given config with credentials", t, func() {
+		cfg := setupCfg("http://localhost:5000", "me", "secret", "admin")
+
+		Convey("When all items are correct", func() {
+			cfg.AddItem("domain_name", ctypes.ConfigValueStr{Value: "Default"})
+			creds, err := getCredentials(loadedSettings(cfg))
+
+			Convey("Then credentials are returned", func() {
+				So(err, ShouldBeNil)
+				So(creds.Endpoint, ShouldEqual, "http://localhost:5000")
+				So(creds.User, ShouldEqual, "me")
+				So(creds.Password, ShouldEqual, "secret")
+				So(creds.DomainName, ShouldEqual, "Default")
+				So(creds.DomainID, ShouldEqual, "")
+			})
+		})
+
+		Convey("When user and project domains are provided", func() {
+			cfg.AddItem("user_domain_name", ctypes.ConfigValueStr{Value: "Default"})
+			cfg.AddItem("project_domain_id", ctypes.ConfigValueStr{Value: "customer_id"})
+			creds, err := getCredentials(loadedSettings(cfg))
+
+			Convey("Then separate domains are returned", func() {
+				So(err, ShouldBeNil)
+				So(creds.UserDomainName, ShouldEqual, "Default")
+				So(creds.ProjectDomainID, ShouldEqual, "customer_id")
+				So(creds.DomainName, ShouldEqual, "")
+			})
+		})
+
+		Convey("When both domain name and domain ID are provided", func() {
+			cfg.AddItem("domain_name", ctypes.ConfigValueStr{Value: "Default"})
+			cfg.AddItem("domain_id", ctypes.ConfigValueStr{Value: "default"})
+			_, err := getCredentials(loadedSettings(cfg))
+
+			Convey("Then mutually exclusive items are reported instead of ignored", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "domain_name and domain_id are mutually exclusive")
+			})
+		})
+
+		Convey("When both project domain name and ID are provided", func() {
+			cfg.AddItem("project_domain_name", ctypes.ConfigValueStr{Value: "customer"})
+			cfg.AddItem("project_domain_id", ctypes.ConfigValueStr{Value: "customer_id"})
+			_, err := getCredentials(loadedSettings(cfg))
+
+			Convey("Then mutually exclusive items are reported", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "project_domain_name and project_domain_id")
+			})
+		})
+
+		Convey("When domain is combined with user domain", func() {
+			cfg.AddItem("domain_name", ctypes.ConfigValueStr{Value: "Default"})
+			cfg.AddItem("user_domain_id", ctypes.ConfigValueStr{Value: "default"})
+			_, err := getCredentials(loadedSettings(cfg))
+
+			Convey("Then conflicting items are reported", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "cannot be combined")
+			})
+		})
+
+		Convey("When item has incorrect type", func() {
+			cfg.AddItem("password", ctypes.ConfigValueInt{Value: 1234})
+			_, err := getCredentials(loadedSettings(cfg))
+
+			Convey("Then descriptive error is returned instead of panic", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "password")
+			})
+		})
+
+		Convey("When application credential is provided along with password", func() {
+			cfg.AddItem("application_credential_id", ctypes.ConfigValueStr{Value: "appcred123"})
+			cfg.AddItem("application_credential_secret", ctypes.ConfigValueStr{Value: "s3cret"})
+			_, err := getCredentials(loadedSettings(cfg))
+
+			Convey("Then mutually exclusive items are reported", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "mutually exclusive")
+			})
+		})
+
+		Convey("When application credential is provided instead of password", func() {
+			node := cdata.NewNode()
+			node.AddItem("endpoint", ctypes.ConfigValueStr{Value: "http://localhost:5000"})
+			node.AddItem("application_credential_id", ctypes.ConfigValueStr{Value: "appcred123"})
+			node.AddItem("application_credential_secret", ctypes.ConfigValueStr{Value: "s3cret"})
+			creds, err := getCredentials(loadedSettings(plugin.ConfigType{ConfigDataNode: node}))
+
+			Convey("Then application credential is returned", func() {
+				So(err, ShouldBeNil)
+				So(creds.UsesApplicationCredential(), ShouldBeTrue)
+				So(creds.ApplicationCredentialID, ShouldEqual, "appcred123")
+				So(creds.ApplicationCredentialSecret, ShouldEqual, "s3cret")
+			})
+		})
+
+		Convey("When application credential secret is missing", func() {
+			node := cdata.NewNode()
+			node.AddItem("endpoint", ctypes.ConfigValueStr{Value: "http://localhost:5000"})
+			node.AddItem("application_credential_name", ctypes.ConfigValueStr{Value: "monitoring"})
+			node.AddItem("user", ctypes.ConfigValueStr{Value: "me"})
+			_, err := getCredentials(loadedSettings(plugin.ConfigType{ConfigDataNode: node}))
+
+			Convey("Then descriptive error is returned", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "application_credential_secret")
+			})
+		})
+
+		Convey("When token is provided instead of password", func() {
+			node := cdata.NewNode()
+			node.AddItem("endpoint", ctypes.ConfigValueStr{Value: "http://localhost:5000"})
+			node.AddItem("token_file", ctypes.ConfigValueStr{Value: "/run/secrets/keystone_token"})
+			creds, err := getCredentials(loadedSettings(plugin.ConfigType{ConfigDataNode: node}))
+
+			Convey("Then token file is returned", func() {
+				So(err, ShouldBeNil)
+				So(creds.UsesToken(), ShouldBeTrue)
+				So(creds.TokenFile, ShouldEqual, "/run/secrets/keystone_token")
+			})
+		})
+
+		Convey("When token is provided along with password", func() {
+			cfg.AddItem("token", ctypes.ConfigValueStr{Value: "2ed210f132564f21b178afb197ee99e3"})
+			_, err := getCredentials(loadedSettings(cfg))
+
+			Convey("Then mutually exclusive items are reported", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "password, token are mutually exclusive")
+			})
+		})
+
+		Convey("When TLS settings are provided", func() {
+			cfg.AddItem("ca_file", ctypes.ConfigValueStr{Value: "/etc/ssl/certs/cloud-ca.pem"})
+			cfg.AddItem("insecure", ctypes.ConfigValueBool{Value: true})
+			creds, err := getCredentials(loadedSettings(cfg))
+
+			Convey("Then TLS settings are returned along with credentials", func() {
+				So(err, ShouldBeNil)
+				So(creds.CAFile, ShouldEqual, "/etc/ssl/certs/cloud-ca.pem")
+				So(creds.Insecure, ShouldBeTrue)
+			})
+		})
+
+		Convey("When client certificate is provided without key", func() {
+			cfg.AddItem("cert_file", ctypes.ConfigValueStr{Value: "/etc/snap/client.pem"})
+			_, err := getCredentials(loadedSettings(cfg))
+
+			Convey("Then descriptive error is returned", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "key_file")
+			})
+		})
+
+		Convey("When required item is missing", func() {
+			node := cdata.NewNode()
+			node.AddItem("endpoint", ctypes.ConfigValueStr{Value: "http://localhost:5000"})
+			node.AddItem("password", ctypes.ConfigValueStr{Value: "secret"})
+			_, err := getCredentials(loadedSettings(plugin.ConfigType{ConfigDataNode: node}))
+
+			Convey("Then descriptive error is returned", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "user")
+			})
+		})
+
+		Convey("When credentials are missing", func() {
+			node := cdata.NewNode()
+			node.AddItem("endpoint", ctypes.ConfigValueStr{Value: "http://localhost:5000"})
+			_, err := loadSettings(plugin.ConfigType{ConfigDataNode: node})
+
+			Convey("Then endpoint is not combined with OS_* environment variables", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "endpoint cannot be combined")
+			})
+		})
+	})
+}
+
+const cloudsYAML = `
+clouds:
+  mycloud:
+    auth:
+      auth_url: http://keystone.example.com:5000/v3
+      username: admin
+      password: from-clouds
+      project_name: admin
+      user_domain_name: Default
+      project_domain_id: customer_id
+    region_name: RegionOne
+    verify: false
+`
+
+func TestGetCredentialsFromCloud(t *testing.T) {
+	Convey("Given clouds.yaml with cloud settings", t, func() {
+		dir, err := ioutil.TempDir("", "clouds")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		cloudsFile := filepath.Join(dir, "clouds.yaml")
+		secureFile := filepath.Join(dir, "secure.yaml")
+		So(ioutil.WriteFile(cloudsFile, []byte(cloudsYAML), 0600), ShouldBeNil)
+		So(ioutil.WriteFile(secureFile, []byte("clouds: {}"), 0600), ShouldBeNil)
+		defer os.Unsetenv("OS_CLIENT_CONFIG_FILE")
+		defer os.Unsetenv("OS_CLIENT_SECURE_FILE")
+		os.Setenv("OS_CLIENT_CONFIG_FILE", cloudsFile)
+		os.Setenv("OS_CLIENT_SECURE_FILE", secureFile)
+
+		node := cdata.NewNode()
+		node.AddItem("cloud", ctypes.ConfigValueStr{Value: "mycloud"})
+		cfg := plugin.ConfigType{ConfigDataNode: node}
+
+		Convey("When only cloud is configured", func() {
+			creds, err := getCredentials(loadedSettings(cfg))
+
+			Convey("Then credentials are read from clouds.yaml", func() {
+				So(err, ShouldBeNil)
+				So(creds.Endpoint, ShouldEqual, "http://keystone.example.com:5000/v3")
+				So(creds.User, ShouldEqual, "admin")
+				So(creds.Password, ShouldEqual, "from-clouds")
+				So(creds.UserDomainName, ShouldEqual, "Default")
+				So(creds.ProjectDomainID, ShouldEqual, "customer_id")
+				So(creds.DomainName, ShouldEqual, "")
+				So(creds.Insecure, ShouldBeTrue)
+			})
+
+			Convey("and tenant and region are read from clouds.yaml", func() {
+				tenant, err := getStringItem(loadedSettings(cfg), "tenant", true)
+				So(err, ShouldBeNil)
+				So(tenant, ShouldEqual, "admin")
+				regions, _, err := getRegions(loadedSettings(cfg))
+				So(err, ShouldBeNil)
+				So(regions, ShouldResemble, []string{"RegionOne"})
+			})
+		})
 
-	registerIdentityRoot(s, router)
-	s.V1 = "v1/v1ffff"
-	s.V2 = "v2/v2ffff"
-	s.Token = "2ed210f132564f21b178afb197ee99e3"
-	registerIdentityToken(s, router)
-	s.Tenant1Name = "admin"
-	s.Tenant2Name = "demo"
-	s.Tenant1ID = "admin_id123"
-	s.Tenant2ID = "demo_id123"
-	registerIdentityTenants(s, router)
+		Convey("When items are also given in config", func() {
+			cfg.AddItem("region", ctypes.ConfigValueStr{Value: "RegionTwo"})
+			cfg.AddItem("insecure", ctypes.ConfigValueBool{Value: false})
+			s := loadedSettings(cfg)
+			creds, err := getCredentials(s)
 
-	registerCinderApi(s)
-	registerCinderLimits(s)
-	s.Vol1 = "vol1id_123"
-	s.Vol2 = "vol2id_321"
-	s.Vol1Size = 11
-	s.Vol2Size = 22
-	registerCinderVolumes(s)
-	s.SnapShotSize = 5
-	registerCinderSnapshots(s)
-}
+			Convey("Then items of config take precedence", func() {
+				So(err, ShouldBeNil)
+				So(creds.User, ShouldEqual, "admin")
+				So(creds.Insecure, ShouldBeFalse)
+				regions, _, err := getRegions(s)
+				So(err, ShouldBeNil)
+				So(regions, ShouldResemble, []string{"RegionTwo"})
+			})
+		})
 
-func (s *CollectorSuite) TearDownSuite() {
-	th.TeardownHTTP()
-	s.server.Close()
-}
+		Convey("When regions are given in config", func() {
+			cfg.AddItem("regions", ctypes.ConfigValueStr{Value: "RegionOne,RegionTwo"})
+			regions, namespaced, err := getRegions(loadedSettings(cfg))
 
-func (s *CollectorSuite) TestGetMetricTypes() {
-	Convey("Given config with enpoint, user and password defined", s.T(), func() {
-		cfg := setupCfg(s.server.URL, "me", "secret", "admin")
+			Convey("Then they take precedence over region of cloud", func() {
+				So(err, ShouldBeNil)
+				So(regions, ShouldResemble, []string{"RegionOne", "RegionTwo"})
+				So(namespaced, ShouldBeTrue)
+			})
+		})
 
-		Convey("When GetMetricTypes() is called", func() {
-			collector := New()
-			mts, err := collector.GetMetricTypes(cfg)
+		Convey("When credentials are also given in config", func() {
+			cfg.AddItem("password", ctypes.ConfigValueStr{Value: "from-config"})
+			_, err := loadSettings(cfg)
 
-			Convey("Then no error should be reported", func() {
+			Convey("Then config is not combined with cloud settings", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "password cannot be combined with settings of cloud mycloud")
+			})
+		})
+
+		Convey("When cloud is selected by OS_CLOUD", func() {
+			defer os.Unsetenv("OS_CLOUD")
+			os.Setenv("OS_CLOUD", "mycloud")
+			node := cdata.NewNode()
+			node.AddItem("tenant", ctypes.ConfigValueStr{Value: "admin"})
+			creds, err := getCredentials(loadedSettings(plugin.ConfigType{ConfigDataNode: node}))
+
+			Convey("Then empty items are read from clouds.yaml", func() {
 				So(err, ShouldBeNil)
+				So(creds.Endpoint, ShouldEqual, "http://keystone.example.com:5000/v3")
 			})
+		})
 
-			Convey("and proper metric types are returned", func() {
-				metricNames := []string{}
-				for _, m := range mts {
-					metricNames = append(metricNames, m.Namespace().String())
+		Convey("When selected cloud is not defined", func() {
+			cfg.AddItem("cloud", ctypes.ConfigValueStr{Value: "othercloud"})
+			_, err := loadSettings(cfg)
 
-				}
+			Convey("Then error is returned", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "othercloud")
+			})
+		})
 
-				So(len(mts), ShouldEqual, 12)
-				So(str.Contains(metricNames, "/intel/openstack/cinder/demo/snapshots/count"), ShouldBeTrue)
-				So(str.Contains(metricNames, "/intel/openstack/cinder/demo/snapshots/bytes"), ShouldBeTrue)
-				So(str.Contains(metricNames, "/intel/openstack/cinder/demo/volumes/count"), ShouldBeTrue)
-				So(str.Contains(metricNames, "/intel/openstack/cinder/demo/volumes/bytes"), ShouldBeTrue)
-				So(str.Contains(metricNames, "/intel/openstack/cinder/demo/limits/MaxTotalVolumeGigabytes"), ShouldBeTrue)
-				So(str.Contains(metricNames, "/intel/openstack/cinder/demo/limits/MaxTotalVolumes"), ShouldBeTrue)
-				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/volumes/count"), ShouldBeTrue)
-				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/volumes/bytes"), ShouldBeTrue)
-				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/limits/MaxTotalVolumeGigabytes"), ShouldBeTrue)
-				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/limits/MaxTotalVolumes"), ShouldBeTrue)
-				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/snapshots/count"), ShouldBeTrue)
-				So(str.Contains(metricNames, "/intel/openstack/cinder/admin/snapshots/bytes"), ShouldBeTrue)
+		Convey("When cloud is not selected", func() {
+			defer os.Unsetenv("OS_AUTH_URL")
+			defer os.Unsetenv("OS_USERNAME")
+			defer os.Unsetenv("OS_PASSWORD")
+			defer os.Unsetenv("OS_USER_DOMAIN_NAME")
+			defer os.Unsetenv("OS_REGION_NAME")
+			os.Setenv("OS_AUTH_URL", "http://keystone.env.com:5000/v3")
+			os.Setenv("OS_USERNAME", "me")
+			os.Setenv("OS_PASSWORD", "from-env")
+			os.Setenv("OS_USER_DOMAIN_NAME", "Default")
+			os.Setenv("OS_REGION_NAME", "RegionOne")
+
+			Convey("and config lacks endpoint and credentials", func() {
+				node := cdata.NewNode()
+				node.AddItem("tenant", ctypes.ConfigValueStr{Value: "admin"})
+				creds, err := getCredentials(loadedSettings(plugin.ConfigType{ConfigDataNode: node}))
+
+				Convey("Then credentials are read from OS_* environment variables", func() {
+					So(err, ShouldBeNil)
+					So(creds.Endpoint, ShouldEqual, "http://keystone.env.com:5000/v3")
+					So(creds.User, ShouldEqual, "me")
+					So(creds.Password, ShouldEqual, "from-env")
+					So(creds.UserDomainName, ShouldEqual, "Default")
+				})
+			})
+
+			Convey("and config contains endpoint and credentials", func() {
+				cfg := setupCfg("http://localhost:5000", "me", "secret", "admin")
+				cfg.AddItem("domain_name", ctypes.ConfigValueStr{Value: "Default"})
+				s := loadedSettings(cfg)
+				creds, err := getCredentials(s)
+
+				Convey("Then OS_* environment variables are ignored", func() {
+					So(err, ShouldBeNil)
+					So(creds.Endpoint, ShouldEqual, "http://localhost:5000")
+					So(creds.Password, ShouldEqual, "secret")
+					So(creds.UserDomainName, ShouldEqual, "")
+					regions, _, err := getRegions(s)
+					So(err, ShouldBeNil)
+					So(regions, ShouldResemble, []string{""})
+				})
 			})
 		})
 	})
 }
 
-func (s *CollectorSuite) TestCollectMetrics() {
+func TestGetRegions(t *testing.T) {
+	Convey("Given config without regions", t, func() {
+		cfg := setupCfg("http://localhost:5000", "me", "secret", "admin")
 
-	Convey("Given set of metric types", s.T(), func() {
-		cfg := setupCfg(s.server.URL, "me", "secret", "admin")
-		m1 := plugin.MetricType{
-			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "demo", "limits", "MaxTotalVolumeGigabytes"),
-			Config_:    cfg.ConfigDataNode}
-		m2 := plugin.MetricType{
-			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "demo", "volumes", "count"),
-			Config_:    cfg.ConfigDataNode}
-		m3 := plugin.MetricType{
-			Namespace_: core.NewNamespace("intel", "openstack", "cinder", "demo", "snapshots", "bytes"),
-			Config_:    cfg.ConfigDataNode}
+		Convey("When regions are read", func() {
+			regions, namespaced, err := getRegions(loadedSettings(cfg))
 
-		Convey("When ColelctMetrics() is called", func() {
-			collector := New()
+			Convey("Then Cinder endpoints of any region are used without region in namespace", func() {
+				So(err, ShouldBeNil)
+				So(regions, ShouldResemble, []string{""})
+				So(namespaced, ShouldBeFalse)
+			})
+		})
 
-			mts, err := collector.CollectMetrics([]plugin.MetricType{m1, m2, m3})
+		Convey("When single region is given", func() {
+			cfg.AddItem("region", ctypes.ConfigValueStr{Value: "RegionOne"})
+			regions, namespaced, err := getRegions(loadedSettings(cfg))
 
-			Convey("Then no error should be reported", func() {
+			Convey("Then region is returned without region in namespace", func() {
 				So(err, ShouldBeNil)
+				So(regions, ShouldResemble, []string{"RegionOne"})
+				So(namespaced, ShouldBeFalse)
 			})
+		})
 
-			Convey("and proper metric types are returned", func() {
-				metricNames := map[string]interface{}{}
-				for _, m := range mts {
-					ns := m.Namespace().String()
-					metricNames[ns] = m.Data()
-					fmt.Println(ns, "=", m.Data())
+		Convey("When list of regions is given", func() {
+			cfg.AddItem("regions", ctypes.ConfigValueStr{Value: "RegionOne, RegionTwo"})
+			regions, namespaced, err := getRegions(loadedSettings(cfg))
+
+			Convey("Then all regions are returned with region in namespace", func() {
+				So(err, ShouldBeNil)
+				So(regions, ShouldResemble, []string{"RegionOne", "RegionTwo"})
+				So(namespaced, ShouldBeTrue)
+			})
+		})
+
+		Convey("When list of regions contains empty region", func() {
+			cfg.AddItem("regions", ctypes.ConfigValueStr{Value: "RegionOne,,RegionTwo"})
+			_, _, err := getRegions(loadedSettings(cfg))
+
+			Convey("Then error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When both region and regions are given", func() {
+			cfg.AddItem("region", ctypes.ConfigValueStr{Value: "RegionOne"})
+			cfg.AddItem("regions", ctypes.ConfigValueStr{Value: "RegionOne,RegionTwo"})
+			_, _, err := getRegions(loadedSettings(cfg))
+
+			Convey("Then error is returned", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "mutually exclusive")
+			})
+		})
+
+		Convey("When interface is not given", func() {
+			availability, err := getAvailability(loadedSettings(cfg))
+
+			Convey("Then public interface is used", func() {
+				So(err, ShouldBeNil)
+				So(availability, ShouldEqual, gophercloud.AvailabilityPublic)
+			})
+		})
+
+		Convey("When internal interface is given", func() {
+			cfg.AddItem("interface", ctypes.ConfigValueStr{Value: "internal"})
+			availability, err := getAvailability(loadedSettings(cfg))
+
+			Convey("Then internal interface is used", func() {
+				So(err, ShouldBeNil)
+				So(availability, ShouldEqual, gophercloud.AvailabilityInternal)
+			})
+		})
+
+		Convey("When unknown interface is given", func() {
+			cfg.AddItem("interface", ctypes.ConfigValueStr{Value: "private"})
+			_, err := getAvailability(loadedSettings(cfg))
+
+			Convey("Then error is returned", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "interface")
+			})
+		})
+	})
+}
+
+func TestMetricResource(t *testing.T) {
+	Convey("Given metrics of tenants named like cloud-wide metrics", t, func() {
+		Convey("When resource of tenant metric is resolved", func() {
+			Convey("Then tenant without prefix and its resource are returned", func() {
+				for _, tenant := range []string{"services", "pools", "volume_types", "cache", "_services"} {
+					owner, resource := metricResource([]string{vendor, fs, name, "_" + tenant, "volumes", "count"})
+					So(owner, ShouldEqual, tenant)
+					So(resource, ShouldEqual, volumesResource)
 				}
+			})
+		})
 
-				So(len(mts), ShouldEqual, 3)
+		Convey("When resource of cloud-wide metric is resolved", func() {
+			Convey("Then resource is returned without tenant", func() {
+				owner, resource := metricResource([]string{vendor, fs, name, cinderServices, "*", "*", "state"})
+				So(owner, ShouldEqual, "")
+				So(resource, ShouldEqual, servicesResource)
+				owner, resource = metricResource([]string{vendor, fs, name, pools, "*", "free_capacity_gb"})
+				So(owner, ShouldEqual, "")
+				So(resource, ShouldEqual, poolsResource)
+			})
+		})
+	})
+}
 
-				val, ok := metricNames["/intel/openstack/cinder/demo/limits/MaxTotalVolumeGigabytes"]
-				So(ok, ShouldBeTrue)
-				So(val, ShouldEqual, s.MaxTotalVolumeGigabytes)
+func TestTenantElement(t *testing.T) {
+	Convey("Given names of tenants", t, func() {
+		Convey("When tenant is named like cloud-wide element", func() {
+			Convey("Then its element is prefixed with underscore and maps back to name", func() {
+				for _, tenant := range []string{"services", "pools", "volume_types", "cache", "_services", "__cache"} {
+					So(tenantElement(tenant), ShouldEqual, "_"+tenant)
+					So(tenantName(tenantElement(tenant)), ShouldEqual, tenant)
+				}
+			})
+		})
 
-				val, ok = metricNames["/intel/openstack/cinder/demo/volumes/count"]
-				So(ok, ShouldBeTrue)
-				So(val, ShouldEqual, 1)
+		Convey("When tenant name does not collide", func() {
+			Convey("Then name is used as element", func() {
+				for _, tenant := range []string{"admin", "_admin", "services_demo"} {
+					So(tenantElement(tenant), ShouldEqual, tenant)
+					So(tenantName(tenant), ShouldEqual, tenant)
+				}
+			})
+		})
+	})
+}
 
-				val, ok = metricNames["/intel/openstack/cinder/demo/snapshots/bytes"]
+func TestQuotaUtilization(t *testing.T) {
+	Convey("Given tenant limits", t, func() {
+		limits := types.Limits{
+			MaxTotalVolumes:         10,
+			TotalVolumesUsed:        5,
+			MaxTotalVolumeGigabytes: -1,
+			TotalGigabytesUsed:      100,
+			MaxTotalSnapshots:       0,
+			TotalSnapshotsUsed:      0,
+		}
+
+		Convey("When quota is limited", func() {
+			utilization, ok := quotaUtilization(limits, "volumes")
+
+			Convey("Then percentage of quota in use is returned", func() {
 				So(ok, ShouldBeTrue)
-				So(val, ShouldEqual, s.SnapShotSize*1024*1024*1024)
+				So(utilization, ShouldEqual, 50.0)
+			})
+		})
+
+		Convey("When quota is unlimited", func() {
+			_, ok := quotaUtilization(limits, "gigabytes")
+
+			Convey("Then no utilization is returned", func() {
+				So(ok, ShouldBeFalse)
+			})
+		})
+
+		Convey("When quota is set to zero", func() {
+			_, ok := quotaUtilization(limits, "snapshots")
 
+			Convey("Then no utilization is returned", func() {
+				So(ok, ShouldBeFalse)
 			})
 		})
 	})
@@ -176,6 +1390,13 @@ func TestCollectorSuite(t *testing.T) {
 	suite.Run(t, collectorTestSuite)
 }
 
+// loadedSettings loads settings of given config, error fails the test
+func loadedSettings(cfg interface{}) settings {
+	s, err := loadSettings(cfg)
+	So(err, ShouldBeNil)
+	return s
+}
+
 func setupCfg(endpoint, user, password, tenant string) plugin.ConfigType {
 	node := cdata.NewNode()
 	node.AddItem("endpoint", ctypes.ConfigValueStr{Value: endpoint})
@@ -214,6 +1435,13 @@ func registerIdentityRoot(s *CollectorSuite, r *mux.Router) {
 
 func registerIdentityToken(s *CollectorSuite, r *mux.Router) {
 	r.HandleFunc("/v2.0/tokens", func(w http.ResponseWriter, r *http.Request) {
+		// endpoints of second region are listed in service catalog when multiple regions are enabled
+		volumeV2RegionTwo, volumeRegionTwo := "", ""
+		if atomic.LoadInt32(&s.MultiRegion) == 1 {
+			volumeV2RegionTwo = regionEndpoint(th.Endpoint()+s.V2, "RegionTwo")
+			volumeRegionTwo = regionEndpoint(th.Endpoint()+s.V1, "RegionTwo")
+		}
+
 		fmt.Fprintf(w, `
 				{
 					"access": {
@@ -232,7 +1460,7 @@ func registerIdentityToken(s *CollectorSuite, r *mux.Router) {
 										"internalURL": "%s",
 										"publicURL": "%s",
 										"region": "RegionOne"
-									}
+									}%s
 								],
 								"endpoints_links": [],
 								"name": "cinderv2",
@@ -246,7 +1474,7 @@ func registerIdentityToken(s *CollectorSuite, r *mux.Router) {
 										"internalURL": "%s",
 										"publicURL": "%s",
 										"region": "RegionOne"
-									}
+									}%s
 								],
 								"endpoints_links": [],
 								"name": "cinder",
@@ -271,15 +1499,33 @@ func registerIdentityToken(s *CollectorSuite, r *mux.Router) {
 			th.Endpoint()+s.V2,
 			th.Endpoint()+s.V2,
 			th.Endpoint()+s.V2,
+			volumeV2RegionTwo,
 			th.Endpoint()+s.V1,
 			th.Endpoint()+s.V1,
 			th.Endpoint()+s.V1,
+			volumeRegionTwo,
 			s.Token)
 	})
 }
 
+// regionEndpoint returns service catalog endpoint of given region, internal interface lists no volumes
+func regionEndpoint(url, region string) string {
+	return fmt.Sprintf(`,
+									{
+										"adminURL": "%s",
+										"internalURL": "%s",
+										"publicURL": "%s",
+										"region": "%s"
+									}`, url, th.Endpoint()+"internal/", url, region)
+}
+
 func registerIdentityTenants(s *CollectorSuite, r *mux.Router) {
 	r.HandleFunc("/v2.0/tenants", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.TenantsRequests, 1)
+		if atomic.LoadInt32(&s.TenantsUnavailable) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
 		w.Header().Add("X-Auth-Token", s.Token)
 
 		w.Header().Add("Content-Type", "application/json")
@@ -309,6 +1555,10 @@ func registerIdentityTenants(s *CollectorSuite, r *mux.Router) {
 
 func registerCinderApi(s *CollectorSuite) {
 	th.Mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&s.CinderUnavailable) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
 		w.Header().Add("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 
@@ -348,6 +1598,7 @@ func registerCinderLimits(s *CollectorSuite) {
 	s.MaxTotalVolumeGigabytes = 1000
 	s.MaxTotalVolumes = 10
 	th.Mux.HandleFunc(s.LimitsV2, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.LimitsRequests, 1)
 		fmt.Fprintf(w, `
 				{
 					"limits": {
@@ -370,6 +1621,43 @@ func registerCinderLimits(s *CollectorSuite) {
 	})
 }
 
+func registerCinderQuotaSets(s *CollectorSuite) {
+	th.Mux.HandleFunc("/"+s.V2+"/os-quota-sets/", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.LimitsRequests, 1)
+		th.TestMethod(s.T(), r, "GET")
+		th.TestHeader(s.T(), r, "X-Auth-Token", s.Token)
+		th.TestFormValues(s.T(), r, map[string]string{"usage": "true"})
+
+		tenantID := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		if atomic.LoadInt32(&s.QuotaSetsForbidden) == 1 {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if tenantID != s.Tenant1ID && tenantID != s.Tenant2ID {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		fmt.Fprintf(w, `
+				{
+					"quota_set": {
+						"id": "%s",
+						"volumes": {"limit": %d, "in_use": 2, "reserved": 1},
+						"gigabytes": {"limit": %d, "in_use": 4, "reserved": 0},
+						"snapshots": {"limit": 10, "in_use": 5, "reserved": 0},
+						"backups": {"limit": 10, "in_use": 1, "reserved": 0},
+						"backup_gigabytes": {"limit": 1000, "in_use": 3, "reserved": 0},
+						"per_volume_gigabytes": {"limit": -1, "in_use": 0, "reserved": 0},
+						"volumes_SSD": {"limit": 4, "in_use": 1, "reserved": 0},
+						"gigabytes_SSD": {"limit": 100, "in_use": 22, "reserved": 0}
+					}
+				}
+			`, tenantID, s.MaxTotalVolumes, s.MaxTotalVolumeGigabytes)
+	})
+}
+
 func registerCinderVolumes(s *CollectorSuite) {
 	url := "/v2/v2ffff/volumes/detail" //?all_tenants=true
 	th.Mux.HandleFunc(url, func(w http.ResponseWriter, r *http.Request) {
@@ -379,6 +1667,19 @@ func registerCinderVolumes(s *CollectorSuite) {
 		w.Header().Add("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 
+		// volume of deleted tenant
+		orphaned := ""
+		if atomic.LoadInt32(&s.OrphanedVolume) == 1 {
+			orphaned = `,
+					{
+						"id": "vol3id_orphan",
+						"os-vol-tenant-attr:tenant_id": "deleted_id123",
+						"size": 7,
+						"status": "available",
+						"volume_type": "gold/ha"
+					}`
+		}
+
 		fmt.Fprintf(w, `
 			{
 				"volumes": [
@@ -473,17 +1774,33 @@ func registerCinderVolumes(s *CollectorSuite) {
 							"size": "13287936"
 						},
 						"volume_type": null
-					}
+					}%s
     			]
        		 }
-		`, s.Vol1, s.Tenant1ID, s.Vol1Size, s.Vol2, s.Tenant2ID, s.Vol2Size)
+		`, s.Vol1, s.Tenant1ID, s.Vol1Size, s.Vol2, s.Tenant2ID, s.Vol2Size, orphaned)
 	})
 
 }
 
+func registerCinderInternal(s *CollectorSuite) {
+	th.Mux.HandleFunc("/internal/volumes/detail", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.InternalRequests, 1)
+		th.TestMethod(s.T(), r, "GET")
+		th.TestHeader(s.T(), r, "X-Auth-Token", s.Token)
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		fmt.Fprintf(w, `{"volumes": []}`)
+	})
+}
+
 func registerCinderSnapshots(s *CollectorSuite) {
 	snapshots := "/v2/v2ffff/snapshots/detail"
 	th.Mux.HandleFunc(snapshots, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&s.SnapshotsUnavailable) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
 		th.TestMethod(s.T(), r, "GET")
 		th.TestHeader(s.T(), r, "X-Auth-Token", s.Token)
 		th.TestFormValues(s.T(), r, map[string]string{"all_tenants": "true"})
@@ -504,9 +1821,115 @@ func registerCinderSnapshots(s *CollectorSuite) {
 						"size": %d,
 						"status": "available",
 						"volume_id": "495a1698-ca2f-4e84-8d34-fa544c65ae3d"
+					},
+					{
+						"created_at": "2016-02-22T19:59:15.000000",
+						"id": "snap2cccc",
+						"name": "snapshot_2",
+						"os-extended-snapshot-attributes:project_id": "%s",
+						"size": %d,
+						"status": "error_managing",
+						"volume_id": "495a1698-ca2f-4e84-8d34-fa544c65ae3d"
+					}
+				]
+			}
+		`, s.Tenant2ID, s.SnapShotSize, s.Tenant1ID, s.SnapShotSize)
+	})
+}
+
+func registerCinderPools(s *CollectorSuite) {
+	th.Mux.HandleFunc("/v2/v2ffff/scheduler-stats/get_pools", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(s.T(), r, "GET")
+		th.TestHeader(s.T(), r, "X-Auth-Token", s.Token)
+		th.TestFormValues(s.T(), r, map[string]string{"detail": "true"})
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		fmt.Fprintf(w, `
+			{
+				"pools": [
+					{
+						"name": "cinder@lvm#lvm",
+						"capabilities": {
+							"total_capacity_gb": 100,
+							"free_capacity_gb": 60,
+							"allocated_capacity_gb": 40,
+							"provisioned_capacity_gb": 45,
+							"max_over_subscription_ratio": "20.0",
+							"reserved_percentage": 0
+						}
+					}
+				]
+			}
+		`)
+	})
+}
+
+func registerCinderServices(s *CollectorSuite) {
+	th.Mux.HandleFunc("/v2/v2ffff/os-services", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(s.T(), r, "GET")
+		th.TestHeader(s.T(), r, "X-Auth-Token", s.Token)
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		fmt.Fprintf(w, `
+			{
+				"services": [
+					{
+						"binary": "cinder-scheduler",
+						"host": "controller",
+						"state": "up",
+						"status": "enabled",
+						"updated_at": "2016-02-21T13:28:30.000000",
+						"zone": "nova"
+					},
+					{
+						"binary": "cinder-volume",
+						"host": "controller@lvm",
+						"state": "down",
+						"status": "enabled",
+						"updated_at": "2016-02-21T13:28:30.000000",
+						"zone": "nova"
+					}
+				]
+			}
+		`)
+	})
+}
+
+func registerCinderBackups(s *CollectorSuite) {
+	th.Mux.HandleFunc("/v2/v2ffff/backups/detail", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&s.BackupsUnavailable) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		th.TestMethod(s.T(), r, "GET")
+		th.TestHeader(s.T(), r, "X-Auth-Token", s.Token)
+		th.TestFormValues(s.T(), r, map[string]string{"all_tenants": "true"})
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		fmt.Fprintf(w, `
+			{
+				"backups": [
+					{
+						"created_at": "2016-02-20T10:00:00.000000",
+						"id": "backup1",
+						"name": "backup_1",
+						"size": %d,
+						"status": "available",
+						"volume_id": "%s"
+					},
+					{
+						"created_at": "2016-02-21T10:00:00.000000",
+						"id": "backup2",
+						"name": "backup_2",
+						"size": %d,
+						"status": "error",
+						"volume_id": "%s"
 					}
 				]
 			}
-		`, s.Tenant2ID, s.SnapShotSize)
+		`, s.BackupSize, s.Vol1, s.BackupSize, s.Vol1)
 	})
 }
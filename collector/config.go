@@ -0,0 +1,395 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rackspace/gophercloud"
+
+	"github.com/intelsdi-x/snap/control/plugin/cpolicy"
+
+	"github.com/intelsdi-x/snap-plugin-utilities/config"
+
+	openstackintel "github.com/intelsdi-x/snap-plugin-collector-cinder/openstack"
+	"github.com/intelsdi-x/snap-plugin-collector-cinder/openstack/clouds"
+)
+
+// getCredentials reads Keystone endpoint and credentials from config or cloud settings
+// Exactly one of user and password, application credential with secret, token or token file has to be provided
+// It returns error if required item is missing, any item has incorrect type or mutually exclusive items are set
+func getCredentials(s settings) (openstackintel.AuthOptions, error) {
+	creds := openstackintel.AuthOptions{}
+
+	var err error
+	if creds.Endpoint, err = getStringItem(s, "endpoint", true); err != nil {
+		return creds, err
+	}
+	for item, value := range map[string]*string{
+		"user":                          &creds.User,
+		"password":                      &creds.Password,
+		"domain_name":                   &creds.DomainName,
+		"domain_id":                     &creds.DomainID,
+		"user_domain_name":              &creds.UserDomainName,
+		"user_domain_id":                &creds.UserDomainID,
+		"project_domain_name":           &creds.ProjectDomainName,
+		"project_domain_id":             &creds.ProjectDomainID,
+		"application_credential_id":     &creds.ApplicationCredentialID,
+		"application_credential_name":   &creds.ApplicationCredentialName,
+		"application_credential_secret": &creds.ApplicationCredentialSecret,
+		"token":                         &creds.Token,
+		"token_file":                    &creds.TokenFile,
+		"ca_file":                       &creds.CAFile,
+		"cert_file":                     &creds.CertFile,
+		"key_file":                      &creds.KeyFile,
+	} {
+		if *value, err = getStringItem(s, item, false); err != nil {
+			return creds, err
+		}
+	}
+	if creds.Insecure, err = getBoolItem(s, "insecure", false); err != nil {
+		return creds, err
+	}
+	if (creds.CertFile == "") != (creds.KeyFile == "") {
+		return creds, errors.New("Config items cert_file and key_file have to be set together")
+	}
+
+	// domain is shared by user and project, so it cannot be combined with separate domains
+	for _, pair := range []struct{ name, id, nameValue, idValue string }{
+		{"domain_name", "domain_id", creds.DomainName, creds.DomainID},
+		{"user_domain_name", "user_domain_id", creds.UserDomainName, creds.UserDomainID},
+		{"project_domain_name", "project_domain_id", creds.ProjectDomainName, creds.ProjectDomainID},
+	} {
+		if pair.nameValue != "" && pair.idValue != "" {
+			return creds, fmt.Errorf("Config items %s and %s are mutually exclusive", pair.name, pair.id)
+		}
+	}
+	if (creds.DomainName != "" || creds.DomainID != "") &&
+		(creds.UserDomainName != "" || creds.UserDomainID != "" || creds.ProjectDomainName != "" || creds.ProjectDomainID != "") {
+		return creds, errors.New("Config items domain_name and domain_id cannot be combined with user_domain_* or project_domain_* items")
+	}
+
+	// items of different authentication methods cannot be mixed
+	methods := []string{}
+	if creds.Password != "" {
+		methods = append(methods, "password")
+	}
+	if creds.ApplicationCredentialID != "" {
+		methods = append(methods, "application_credential_id")
+	}
+	if creds.ApplicationCredentialName != "" {
+		methods = append(methods, "application_credential_name")
+	}
+	if creds.Token != "" {
+		methods = append(methods, "token")
+	}
+	if creds.TokenFile != "" {
+		methods = append(methods, "token_file")
+	}
+	if len(methods) > 1 {
+		return creds, fmt.Errorf("Config items %s are mutually exclusive", strings.Join(methods, ", "))
+	}
+
+	switch {
+	case creds.UsesToken():
+		if creds.ApplicationCredentialSecret != "" {
+			return creds, errors.New("Config item application_credential_secret cannot be used with token")
+		}
+	case creds.UsesApplicationCredential():
+		if creds.ApplicationCredentialSecret == "" {
+			return creds, errors.New("Missing required config item: application_credential_secret")
+		}
+		if creds.ApplicationCredentialName != "" && creds.User == "" {
+			return creds, errors.New("Config item application_credential_name requires user")
+		}
+	default:
+		if creds.ApplicationCredentialSecret != "" {
+			return creds, errors.New("Config item application_credential_secret requires application_credential_id or application_credential_name")
+		}
+		if creds.User == "" {
+			return creds, errors.New("Missing required config item: user")
+		}
+		if creds.Password == "" {
+			return creds, errors.New("Missing required config item: password")
+		}
+	}
+
+	return creds, nil
+}
+
+// credentialItems lists config items of Keystone endpoint and credentials, which are read from single source
+var credentialItems = []string{"endpoint", "user", "password", "domain_name", "domain_id",
+	"user_domain_name", "user_domain_id", "project_domain_name", "project_domain_id",
+	"application_credential_id", "application_credential_name", "application_credential_secret", "token", "token_file"}
+
+// settings holds config along with cloud settings, which are nil when config alone is used
+type settings struct {
+	cfg   interface{}
+	cloud map[string]interface{}
+}
+
+// loadSettings selects source of Keystone endpoint and credentials
+// Settings of cloud selected by cloud item or OS_CLOUD environment variable are read from clouds.yaml and secure.yaml,
+// OS_* environment variables are read when cloud is not selected but config lacks endpoint or credentials.
+// Config is used alone otherwise. Endpoint and credentials cannot be given in config along with cloud settings
+func loadSettings(cfg interface{}) (settings, error) {
+	name := os.Getenv("OS_CLOUD")
+	if value, err := config.GetConfigItem(cfg, "cloud"); err == nil {
+		s, ok := value.(string)
+		if !ok {
+			return settings{}, fmt.Errorf("Incorrect type of cloud: expected string, got %T", value)
+		}
+		if s != "" {
+			name = s
+		}
+	}
+
+	if name == "" && configured(cfg, "endpoint") &&
+		(configured(cfg, "password") || configured(cfg, "application_credential_id") || configured(cfg, "application_credential_name") ||
+			configured(cfg, "token") || configured(cfg, "token_file")) {
+		return settings{cfg: cfg}, nil
+	}
+
+	source := "OS_* environment variables, which are used as config lacks endpoint or credentials"
+	if name != "" {
+		source = "settings of cloud " + name
+	}
+	for _, item := range credentialItems {
+		if configured(cfg, item) {
+			return settings{}, fmt.Errorf("Config item %s cannot be combined with %s", item, source)
+		}
+	}
+
+	var cloud clouds.Cloud
+	var err error
+	if name == "" {
+		cloud, err = clouds.FromEnv()
+	} else {
+		cloud, err = clouds.Load(name)
+	}
+	if err != nil {
+		return settings{}, err
+	}
+
+	return settings{cfg: cfg, cloud: cloudItems(cloud)}, nil
+}
+
+// configured checks whether item is given in config with non-empty value
+func configured(cfg interface{}, item string) bool {
+	value, err := config.GetConfigItem(cfg, item)
+	return err == nil && value != nil && value != ""
+}
+
+// get returns value of item given in config, or found in cloud settings when item is not given in config
+// Items given in config with empty value are treated as not given. It returns nil if item is not provided
+func (s settings) get(item string) interface{} {
+	if configured(s.cfg, item) {
+		value, _ := config.GetConfigItem(s.cfg, item)
+		return value
+	}
+	return s.cloud[item]
+}
+
+// getStringItem reads string item from settings
+// It returns empty string if optional item is not provided
+func getStringItem(s settings, item string, required bool) (string, error) {
+	value := s.get(item)
+	if value == nil {
+		if required {
+			return "", fmt.Errorf("Missing required config item: %s", item)
+		}
+		return "", nil
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("Incorrect type of %s: expected string, got %T", item, value)
+	}
+	if required && str == "" {
+		return "", fmt.Errorf("Missing required config item: %s", item)
+	}
+
+	return str, nil
+}
+
+// getBoolItem reads bool item from settings
+// It returns default value if item is not provided
+func getBoolItem(s settings, item string, def bool) (bool, error) {
+	value := s.get(item)
+	if value == nil {
+		return def, nil
+	}
+
+	b, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("Incorrect type of %s: expected bool, got %T", item, value)
+	}
+
+	return b, nil
+}
+
+// cloudItems maps cloud settings to config items, empty settings are omitted
+func cloudItems(cloud clouds.Cloud) map[string]interface{} {
+	items := map[string]interface{}{}
+	for item, value := range map[string]string{
+		"endpoint":                      cloud.Auth.AuthURL,
+		"tenant":                        cloud.Project(),
+		"user":                          cloud.Auth.Username,
+		"password":                      cloud.Auth.Password,
+		"domain_name":                   cloud.Auth.DomainName,
+		"domain_id":                     cloud.Auth.DomainID,
+		"user_domain_name":              cloud.Auth.UserDomainName,
+		"user_domain_id":                cloud.Auth.UserDomainID,
+		"project_domain_name":           cloud.Auth.ProjectDomainName,
+		"project_domain_id":             cloud.Auth.ProjectDomainID,
+		"application_credential_id":     cloud.Auth.ApplicationCredentialID,
+		"application_credential_name":   cloud.Auth.ApplicationCredentialName,
+		"application_credential_secret": cloud.Auth.ApplicationCredentialSecret,
+		"token":                         cloud.Auth.Token,
+		"region":                        cloud.RegionName,
+		"interface":                     cloud.Interface,
+		"ca_file":                       cloud.CACert,
+		"cert_file":                     cloud.Cert,
+		"key_file":                      cloud.Key,
+	} {
+		if value != "" {
+			items[item] = value
+		}
+	}
+	if cloud.Verify != nil {
+		items["insecure"] = !*cloud.Verify
+	}
+
+	return items
+}
+
+// getPageSize reads number of volumes, snapshots and backups listed in single request from config
+// It returns 0 if item is not provided, so that Cinder default page size is used
+func getPageSize(cfg interface{}) (int, error) {
+	value, err := config.GetConfigItem(cfg, "page_size")
+	if err != nil {
+		return 0, nil
+	}
+
+	pageSize, ok := value.(int)
+	if !ok {
+		return 0, fmt.Errorf("Incorrect type of page_size: expected integer, got %T", value)
+	}
+	if pageSize < 0 {
+		return 0, fmt.Errorf("Incorrect value of page_size: %d", pageSize)
+	}
+
+	return pageSize, nil
+}
+
+// getRegions reads regions of Cinder endpoints which metrics are collected from
+// Single region given in region item does not change namespace, regions item lists comma separated regions collected in one task
+// and metrics are namespaced by region. It returns list with empty region if none of items is provided
+func getRegions(s settings) ([]string, bool, error) {
+	region, err := getStringItem(s, "region", false)
+	if err != nil {
+		return nil, false, err
+	}
+	list, err := getStringItem(s, "regions", false)
+	if err != nil {
+		return nil, false, err
+	}
+	if list == "" {
+		return []string{region}, false, nil
+	}
+	// regions given in config take precedence over region of cloud settings
+	if configured(s.cfg, "region") {
+		return nil, false, errors.New("Config items region and regions are mutually exclusive")
+	}
+
+	regions := []string{}
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, false, fmt.Errorf("Incorrect value of regions: empty region in %q", list)
+		}
+		regions = append(regions, name)
+	}
+
+	return regions, true, nil
+}
+
+// getAvailability reads interface of Cinder endpoints from config
+// It returns public interface if item is not provided
+func getAvailability(s settings) (gophercloud.Availability, error) {
+	value, err := getStringItem(s, "interface", false)
+	if err != nil {
+		return "", err
+	}
+
+	switch availability := gophercloud.Availability(value); availability {
+	case "":
+		return gophercloud.AvailabilityPublic, nil
+	case gophercloud.AvailabilityPublic, gophercloud.AvailabilityInternal, gophercloud.AvailabilityAdmin:
+		return availability, nil
+	default:
+		return "", fmt.Errorf("Incorrect value of interface: expected public, internal or admin, got %s", value)
+	}
+}
+
+// configPolicy declares all config items supported by collector
+// Endpoint and credentials are read either from config or from cloud settings (see loadSettings), other items given
+// in task or global config take precedence over cloud settings
+// Items which may be resolved from cloud settings are therefore optional and have no defaults in policy
+func configPolicy() (*cpolicy.ConfigPolicyNode, error) {
+	policy := cpolicy.NewPolicyNode()
+
+	// endpoint and tenant are required unless read from cloud settings, policy cannot express such condition,
+	// so they are optional here and missing items are reported when config is read
+	// single authentication method is used, exclusivity is validated when config is read
+	for _, item := range []string{"cloud", "endpoint", "tenant", "user", "password", "domain_name", "domain_id",
+		"user_domain_name", "user_domain_id", "project_domain_name", "project_domain_id",
+		"application_credential_id", "application_credential_name", "application_credential_secret",
+		"token", "token_file", "ca_file", "cert_file", "key_file", "region", "regions", "interface"} {
+		rule, err := cpolicy.NewStringRule(item, false)
+		if err != nil {
+			return nil, err
+		}
+		policy.Add(rule)
+	}
+
+	limitsTTL, err := cpolicy.NewStringRule("limits_ttl", false, defaultLimitsTTL.String())
+	if err != nil {
+		return nil, err
+	}
+	tenantsTTL, err := cpolicy.NewStringRule("tenants_ttl", false, defaultTenantsTTL.String())
+	if err != nil {
+		return nil, err
+	}
+	partial, err := cpolicy.NewBoolRule("partial_metrics", false, false)
+	if err != nil {
+		return nil, err
+	}
+	insecure, err := cpolicy.NewBoolRule("insecure", false)
+	if err != nil {
+		return nil, err
+	}
+	pageSize, err := cpolicy.NewIntegerRule("page_size", false)
+	if err != nil {
+		return nil, err
+	}
+	pageSize.SetMinimum(0)
+	policy.Add(limitsTTL, tenantsTTL, partial, insecure, pageSize)
+
+	return policy, nil
+}
@@ -0,0 +1,63 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collector
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CollectionError describes failure of single resource collection
+// Tenant is empty for resources collected for all tenants at once, Region is empty when region is not configured
+type CollectionError struct {
+	Region   string
+	Tenant   string
+	Resource string
+	Err      error
+}
+
+// Error returns description of failed collection
+func (e CollectionError) Error() string {
+	resource := e.Resource
+	if e.Tenant != "" {
+		resource = fmt.Sprintf("%s for tenant %s", resource, e.Tenant)
+	}
+	if e.Region != "" {
+		resource = fmt.Sprintf("%s in region %s", resource, e.Region)
+	}
+	return fmt.Sprintf("collection of %s failed: %v", resource, e.Err)
+}
+
+// MultiError gathers all collection errors which occurred during single collection
+type MultiError []CollectionError
+
+// Error returns descriptions of all failed collections
+func (m MultiError) Error() string {
+	msgs := make([]string, len(m))
+	for i, e := range m {
+		msgs[i] = e.Error()
+	}
+	return fmt.Sprintf("%d collection error(s) occurred: %s", len(m), strings.Join(msgs, "; "))
+}
+
+// Failed checks whether collection of resource for given tenant failed
+func (m MultiError) Failed(tenant, resource string) bool {
+	for _, e := range m {
+		if e.Resource == resource && (e.Tenant == "" || e.Tenant == tenant) {
+			return true
+		}
+	}
+	return false
+}
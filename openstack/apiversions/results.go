@@ -0,0 +1,41 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// results contains Cinder API versions responses and their processing
+
+package apiversions
+
+import (
+	"github.com/mitchellh/mapstructure"
+	"github.com/rackspace/gophercloud/openstack/blockstorage/v1/apiversions"
+	"github.com/rackspace/gophercloud/pagination"
+)
+
+// APIVersion contains information about Cinder API version
+// Version and MinVersion are range of supported microversions, empty if API version does not support microversions
+type APIVersion struct {
+	ID         string `mapstructure:"id"`
+	Status     string `mapstructure:"status"`
+	Version    string `mapstructure:"version"`
+	MinVersion string `mapstructure:"min_version"`
+}
+
+// ExtractAPIVersions extracts and returns API versions along with supported microversions
+func ExtractAPIVersions(page pagination.Page) ([]APIVersion, error) {
+	var response struct {
+		Versions []APIVersion `mapstructure:"versions"`
+	}
+	err := mapstructure.Decode(page.(apiversions.APIVersionPage).Body, &response)
+	return response.Versions, err
+}
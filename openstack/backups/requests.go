@@ -0,0 +1,68 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// requests contains Cinder API requests for volume backups
+
+package backups
+
+import (
+	"github.com/rackspace/gophercloud"
+	"github.com/rackspace/gophercloud/pagination"
+)
+
+// ListOptsBuilder allows extensions to add additional parameters to the List request.
+type ListOptsBuilder interface {
+	ToBackupListQuery() (string, error)
+}
+
+// ListOpts holds options for listing Backups. It is passed to the backups.List function.
+type ListOpts struct {
+	// admin-only option. Set it to true to see all tenant backups.
+	AllTenants bool `q:"all_tenants"`
+	// List only backups that have a status of Status.
+	Status string `q:"status"`
+	// List only backups of volume with VolumeID.
+	VolumeID string `q:"volume_id"`
+	// Maximum number of backups returned in single page, Cinder default is used when not set.
+	Limit int `q:"limit"`
+	// List only backups after backup with ID of Marker.
+	Marker string `q:"marker"`
+}
+
+// ToBackupListQuery formats a ListOpts into a query string.
+func (opts ListOpts) ToBackupListQuery() (string, error) {
+	q, err := gophercloud.BuildQueryString(opts)
+	if err != nil {
+		return "", err
+	}
+	return q.String(), nil
+}
+
+// List returns Backups optionally limited by the conditions provided in ListOpts.
+// Following pages are linked with backups_links
+func List(client *gophercloud.ServiceClient, opts ListOptsBuilder) pagination.Pager {
+	url := listURL(client)
+	if opts != nil {
+		query, err := opts.ToBackupListQuery()
+		if err != nil {
+			return pagination.Pager{Err: err}
+		}
+		url += query
+	}
+
+	createPage := func(r pagination.PageResult) pagination.Page {
+		return ListResult{pagination.LinkedPageBase{PageResult: r}}
+	}
+	return pagination.NewPager(client, url, createPage)
+}
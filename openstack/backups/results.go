@@ -0,0 +1,95 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// results contains Cinder API responses and their processing for volume backups
+
+package backups
+
+import (
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/rackspace/gophercloud/pagination"
+)
+
+// createdAtFormats lists time formats used by Cinder for created_at field
+var createdAtFormats = []string{"2006-01-02T15:04:05.000000", "2006-01-02T15:04:05", time.RFC3339}
+
+// Backup contains information associated with an OpenStack volume backup
+type Backup struct {
+	ID        string `mapstructure:"id"`
+	Name      string `mapstructure:"name"`
+	Status    string `mapstructure:"status"`
+	Size      int    `mapstructure:"size"`
+	VolumeID  string `mapstructure:"volume_id"`
+	CreatedAt string `mapstructure:"created_at"`
+	// The project ID which the backup belongs to, reported only by newer Cinder releases
+	ProjectID string `mapstructure:"os-backup-project-attr:project_id"`
+}
+
+// Created returns backup creation time, zero time is returned if it cannot be parsed
+func (b Backup) Created() time.Time {
+	for _, format := range createdAtFormats {
+		created, err := time.Parse(format, b.CreatedAt)
+		if err == nil {
+			return created
+		}
+	}
+	return time.Time{}
+}
+
+// ListResult is a pagination.Pager that is returned from a call to the List function.
+type ListResult struct {
+	pagination.LinkedPageBase
+}
+
+// NextPageURL returns URL of the next page taken from backups_links, it is empty for the last page
+func (r ListResult) NextPageURL() (string, error) {
+	var response struct {
+		Links []struct {
+			Href string `mapstructure:"href"`
+			Rel  string `mapstructure:"rel"`
+		} `mapstructure:"backups_links"`
+	}
+
+	if err := mapstructure.Decode(r.Body, &response); err != nil {
+		return "", err
+	}
+
+	for _, link := range response.Links {
+		if link.Rel == "next" {
+			return link.Href, nil
+		}
+	}
+	return "", nil
+}
+
+// IsEmpty returns true if a ListResult contains no Backups.
+func (r ListResult) IsEmpty() (bool, error) {
+	backups, err := ExtractBackups(r)
+	if err != nil {
+		return true, err
+	}
+	return len(backups) == 0, nil
+}
+
+// ExtractBackups extracts and returns Backups. It is used while iterating over a backups.List call.
+func ExtractBackups(page pagination.Page) ([]Backup, error) {
+	var response struct {
+		Backups []Backup `mapstructure:"backups"`
+	}
+
+	err := mapstructure.Decode(page.(ListResult).Body, &response)
+	return response.Backups, err
+}
@@ -0,0 +1,64 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// tenants contains aggregation of volume backups per tenant shared by Cinder API versions
+
+package backups
+
+import (
+	"github.com/rackspace/gophercloud"
+	"github.com/rackspace/gophercloud/pagination"
+
+	"github.com/intelsdi-x/snap-plugin-collector-cinder/types"
+)
+
+// TenantBackups lists backups of all tenants page by page and aggregates them per tenant
+// Backups are assigned to tenants owning backed up volumes when Cinder does not report backup project,
+// volumeOwners is called to list tenant ID of each volume only when such backup is found
+func TenantBackups(client *gophercloud.ServiceClient, pageSize int, volumeOwners func() (map[string]string, error)) (map[string]types.TenantBackups, error) {
+	backs := map[string]types.TenantBackups{}
+
+	var owners map[string]string
+	opts := ListOpts{AllTenants: true, Limit: pageSize}
+	pager := List(client, opts)
+	err := pager.EachPage(func(page pagination.Page) (bool, error) {
+		backupList, err := ExtractBackups(page)
+		if err != nil {
+			return false, err
+		}
+
+		for _, backup := range backupList {
+			tenant := backup.ProjectID
+			if tenant == "" {
+				if owners == nil {
+					owners, err = volumeOwners()
+					if err != nil {
+						return false, err
+					}
+				}
+				tenant = owners[backup.VolumeID]
+			}
+
+			backCounts := backs[tenant]
+			backCounts.Add(backup.Size, backup.Status, backup.Created())
+			backs[tenant] = backCounts
+		}
+		return true, nil
+	})
+	if err != nil {
+		return backs, err
+	}
+
+	return backs, nil
+}
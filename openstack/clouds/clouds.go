@@ -0,0 +1,217 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Clouds package reads cloud settings shared with other OpenStack tools
+// Settings are read from clouds.yaml (with secrets from secure.yaml) or from OS_* environment variables
+
+package clouds
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Auth holds authentication settings of cloud
+type Auth struct {
+	AuthURL                     string `yaml:"auth_url"`
+	Username                    string `yaml:"username"`
+	Password                    string `yaml:"password"`
+	ProjectName                 string `yaml:"project_name"`
+	TenantName                  string `yaml:"tenant_name"`
+	DomainName                  string `yaml:"domain_name"`
+	DomainID                    string `yaml:"domain_id"`
+	UserDomainName              string `yaml:"user_domain_name"`
+	UserDomainID                string `yaml:"user_domain_id"`
+	ProjectDomainName           string `yaml:"project_domain_name"`
+	ProjectDomainID             string `yaml:"project_domain_id"`
+	ApplicationCredentialID     string `yaml:"application_credential_id"`
+	ApplicationCredentialName   string `yaml:"application_credential_name"`
+	ApplicationCredentialSecret string `yaml:"application_credential_secret"`
+	Token                       string `yaml:"token"`
+}
+
+// Cloud holds settings of single cloud
+// Verify is nil when certificate verification is not configured
+type Cloud struct {
+	Auth       Auth   `yaml:"auth"`
+	RegionName string `yaml:"region_name"`
+	Interface  string `yaml:"interface"`
+	CACert     string `yaml:"cacert"`
+	Cert       string `yaml:"cert"`
+	Key        string `yaml:"key"`
+	Verify     *bool  `yaml:"verify"`
+}
+
+// Project returns name of project the cloud is scoped to, tenant_name is used by older configurations
+func (c Cloud) Project() string {
+	if c.Auth.ProjectName != "" {
+		return c.Auth.ProjectName
+	}
+	return c.Auth.TenantName
+}
+
+// config holds content of clouds.yaml or secure.yaml
+type config struct {
+	Clouds map[string]Cloud `yaml:"clouds"`
+}
+
+// searchPath lists directories searched for clouds.yaml and secure.yaml, in order of priority
+func searchPath() []string {
+	dirs := []string{"."}
+	if home := os.Getenv("HOME"); home != "" {
+		dirs = append(dirs, filepath.Join(home, ".config", "openstack"))
+	}
+	return append(dirs, "/etc/openstack")
+}
+
+// findFile returns path of configuration file given by environment variable or first file with given name found in search path
+// It returns empty path if file is not found
+func findFile(env, name string) string {
+	if file := os.Getenv(env); file != "" {
+		return file
+	}
+	for _, dir := range searchPath() {
+		file := filepath.Join(dir, name)
+		if _, err := os.Stat(file); err == nil {
+			return file
+		}
+	}
+	return ""
+}
+
+// readConfig parses clouds.yaml or secure.yaml
+func readConfig(file string) (config, error) {
+	cfg := config{}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return cfg, err
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("Cannot parse %s: %v", file, err)
+	}
+
+	return cfg, nil
+}
+
+// Load returns settings of given cloud from clouds.yaml
+// Non-empty settings found in secure.yaml take precedence over clouds.yaml, so that secrets can be kept separately
+// Files are looked up in OS_CLIENT_CONFIG_FILE and OS_CLIENT_SECURE_FILE, current directory, ~/.config/openstack and /etc/openstack
+func Load(name string) (Cloud, error) {
+	file := findFile("OS_CLIENT_CONFIG_FILE", "clouds.yaml")
+	if file == "" {
+		return Cloud{}, fmt.Errorf("Cannot find clouds.yaml for cloud %s", name)
+	}
+	cfg, err := readConfig(file)
+	if err != nil {
+		return Cloud{}, err
+	}
+	cloud, found := cfg.Clouds[name]
+	if !found {
+		return Cloud{}, fmt.Errorf("Cloud %s not found in %s", name, file)
+	}
+
+	if file := findFile("OS_CLIENT_SECURE_FILE", "secure.yaml"); file != "" {
+		secure, err := readConfig(file)
+		if err != nil {
+			return Cloud{}, err
+		}
+		cloud = merge(cloud, secure.Clouds[name])
+	}
+
+	return cloud, nil
+}
+
+// merge returns cloud with settings overridden by non-empty settings of other cloud
+func merge(cloud, other Cloud) Cloud {
+	for _, s := range []struct {
+		dst *string
+		src string
+	}{
+		{&cloud.Auth.AuthURL, other.Auth.AuthURL},
+		{&cloud.Auth.Username, other.Auth.Username},
+		{&cloud.Auth.Password, other.Auth.Password},
+		{&cloud.Auth.ProjectName, other.Auth.ProjectName},
+		{&cloud.Auth.TenantName, other.Auth.TenantName},
+		{&cloud.Auth.DomainName, other.Auth.DomainName},
+		{&cloud.Auth.DomainID, other.Auth.DomainID},
+		{&cloud.Auth.UserDomainName, other.Auth.UserDomainName},
+		{&cloud.Auth.UserDomainID, other.Auth.UserDomainID},
+		{&cloud.Auth.ProjectDomainName, other.Auth.ProjectDomainName},
+		{&cloud.Auth.ProjectDomainID, other.Auth.ProjectDomainID},
+		{&cloud.Auth.ApplicationCredentialID, other.Auth.ApplicationCredentialID},
+		{&cloud.Auth.ApplicationCredentialName, other.Auth.ApplicationCredentialName},
+		{&cloud.Auth.ApplicationCredentialSecret, other.Auth.ApplicationCredentialSecret},
+		{&cloud.Auth.Token, other.Auth.Token},
+		{&cloud.RegionName, other.RegionName},
+		{&cloud.Interface, other.Interface},
+		{&cloud.CACert, other.CACert},
+		{&cloud.Cert, other.Cert},
+		{&cloud.Key, other.Key},
+	} {
+		if s.src != "" {
+			*s.dst = s.src
+		}
+	}
+	if other.Verify != nil {
+		cloud.Verify = other.Verify
+	}
+
+	return cloud
+}
+
+// FromEnv returns cloud settings given in OS_* environment variables
+// It returns error if OS_INSECURE is not a boolean
+func FromEnv() (Cloud, error) {
+	cloud := Cloud{
+		Auth: Auth{
+			AuthURL:                     os.Getenv("OS_AUTH_URL"),
+			Username:                    os.Getenv("OS_USERNAME"),
+			Password:                    os.Getenv("OS_PASSWORD"),
+			ProjectName:                 os.Getenv("OS_PROJECT_NAME"),
+			TenantName:                  os.Getenv("OS_TENANT_NAME"),
+			DomainName:                  os.Getenv("OS_DOMAIN_NAME"),
+			DomainID:                    os.Getenv("OS_DOMAIN_ID"),
+			UserDomainName:              os.Getenv("OS_USER_DOMAIN_NAME"),
+			UserDomainID:                os.Getenv("OS_USER_DOMAIN_ID"),
+			ProjectDomainName:           os.Getenv("OS_PROJECT_DOMAIN_NAME"),
+			ProjectDomainID:             os.Getenv("OS_PROJECT_DOMAIN_ID"),
+			ApplicationCredentialID:     os.Getenv("OS_APPLICATION_CREDENTIAL_ID"),
+			ApplicationCredentialName:   os.Getenv("OS_APPLICATION_CREDENTIAL_NAME"),
+			ApplicationCredentialSecret: os.Getenv("OS_APPLICATION_CREDENTIAL_SECRET"),
+			Token:                       os.Getenv("OS_TOKEN"),
+		},
+		RegionName: os.Getenv("OS_REGION_NAME"),
+		Interface:  os.Getenv("OS_INTERFACE"),
+		CACert:     os.Getenv("OS_CACERT"),
+		Cert:       os.Getenv("OS_CERT"),
+		Key:        os.Getenv("OS_KEY"),
+	}
+
+	if value := os.Getenv("OS_INSECURE"); value != "" {
+		insecure, err := strconv.ParseBool(value)
+		if err != nil {
+			return cloud, fmt.Errorf("Incorrect value of OS_INSECURE: %s", value)
+		}
+		verify := !insecure
+		cloud.Verify = &verify
+	}
+
+	return cloud, nil
+}
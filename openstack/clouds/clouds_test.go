@@ -0,0 +1,160 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clouds
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const cloudsYAML = `
+clouds:
+  mycloud:
+    auth:
+      auth_url: https://keystone.example.com:5000/v3
+      username: admin
+      password: from-clouds
+      project_name: admin
+      user_domain_name: Default
+      project_domain_name: customer
+    region_name: RegionOne
+    interface: internal
+    cacert: /etc/ssl/private-ca.pem
+    verify: true
+`
+
+const secureYAML = `
+clouds:
+  mycloud:
+    auth:
+      password: from-secure
+    verify: false
+`
+
+func TestLoad(t *testing.T) {
+	Convey("Given clouds.yaml and secure.yaml", t, func() {
+		dir, err := ioutil.TempDir("", "clouds")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		cloudsFile := filepath.Join(dir, "clouds.yaml")
+		secureFile := filepath.Join(dir, "secure.yaml")
+		So(ioutil.WriteFile(cloudsFile, []byte(cloudsYAML), 0600), ShouldBeNil)
+		So(ioutil.WriteFile(secureFile, []byte(secureYAML), 0600), ShouldBeNil)
+
+		defer os.Unsetenv("OS_CLIENT_CONFIG_FILE")
+		defer os.Unsetenv("OS_CLIENT_SECURE_FILE")
+		os.Setenv("OS_CLIENT_CONFIG_FILE", cloudsFile)
+		os.Setenv("OS_CLIENT_SECURE_FILE", secureFile)
+
+		Convey("When cloud is loaded", func() {
+			cloud, err := Load("mycloud")
+
+			Convey("Then settings of clouds.yaml are returned", func() {
+				So(err, ShouldBeNil)
+				So(cloud.Auth.AuthURL, ShouldEqual, "https://keystone.example.com:5000/v3")
+				So(cloud.Auth.Username, ShouldEqual, "admin")
+				So(cloud.Project(), ShouldEqual, "admin")
+				So(cloud.Auth.UserDomainName, ShouldEqual, "Default")
+				So(cloud.Auth.ProjectDomainName, ShouldEqual, "customer")
+				So(cloud.RegionName, ShouldEqual, "RegionOne")
+				So(cloud.Interface, ShouldEqual, "internal")
+				So(cloud.CACert, ShouldEqual, "/etc/ssl/private-ca.pem")
+			})
+
+			Convey("and settings of secure.yaml take precedence", func() {
+				So(cloud.Auth.Password, ShouldEqual, "from-secure")
+				So(*cloud.Verify, ShouldBeFalse)
+			})
+		})
+
+		Convey("When secure.yaml does not exist", func() {
+			os.Setenv("OS_CLIENT_SECURE_FILE", filepath.Join(dir, "missing.yaml"))
+			_, err := Load("mycloud")
+
+			Convey("Then error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When cloud is not defined", func() {
+			_, err := Load("othercloud")
+
+			Convey("Then error is returned", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "othercloud")
+			})
+		})
+
+		Convey("When clouds.yaml is malformed", func() {
+			So(ioutil.WriteFile(cloudsFile, []byte("clouds: ["), 0600), ShouldBeNil)
+			_, err := Load("mycloud")
+
+			Convey("Then error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+func TestFromEnv(t *testing.T) {
+	Convey("Given OS_* environment variables", t, func() {
+		env := map[string]string{
+			"OS_AUTH_URL":       "https://keystone.example.com:5000/v3",
+			"OS_USERNAME":       "admin",
+			"OS_PASSWORD":       "secret",
+			"OS_TENANT_NAME":    "admin",
+			"OS_REGION_NAME":    "RegionTwo",
+			"OS_INSECURE":       "true",
+			"OS_INTERFACE":      "admin",
+			"OS_USER_DOMAIN_ID": "default",
+		}
+		for key, value := range env {
+			os.Setenv(key, value)
+			defer os.Unsetenv(key)
+		}
+
+		Convey("When cloud settings are read from environment", func() {
+			cloud, err := FromEnv()
+
+			Convey("Then settings of environment variables are returned", func() {
+				So(err, ShouldBeNil)
+				So(cloud.Auth.AuthURL, ShouldEqual, "https://keystone.example.com:5000/v3")
+				So(cloud.Auth.Username, ShouldEqual, "admin")
+				So(cloud.Auth.Password, ShouldEqual, "secret")
+				So(cloud.Project(), ShouldEqual, "admin")
+				So(cloud.Auth.UserDomainID, ShouldEqual, "default")
+				So(cloud.RegionName, ShouldEqual, "RegionTwo")
+				So(cloud.Interface, ShouldEqual, "admin")
+				So(*cloud.Verify, ShouldBeFalse)
+			})
+		})
+
+		Convey("When OS_INSECURE is not a boolean", func() {
+			os.Setenv("OS_INSECURE", "maybe")
+			_, err := FromEnv()
+
+			Convey("Then error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
@@ -17,26 +17,126 @@ limitations under the License.
 package openstack
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 
 	"github.com/rackspace/gophercloud"
 	"github.com/rackspace/gophercloud/openstack"
-	"github.com/rackspace/gophercloud/openstack/blockstorage/v1/apiversions"
 	"github.com/rackspace/gophercloud/openstack/identity/v2/tenants"
+	tokens3 "github.com/rackspace/gophercloud/openstack/identity/v3/tokens"
+	"github.com/rackspace/gophercloud/openstack/utils"
 
 	apiversionsintel "github.com/intelsdi-x/snap-plugin-collector-cinder/openstack/apiversions"
+	domainsintel "github.com/intelsdi-x/snap-plugin-collector-cinder/openstack/domains"
+	projectsintel "github.com/intelsdi-x/snap-plugin-collector-cinder/openstack/projects"
+	tokensintel "github.com/intelsdi-x/snap-plugin-collector-cinder/openstack/tokens"
 	openstackintel "github.com/intelsdi-x/snap-plugin-collector-cinder/openstack/v2"
 )
 
+// ErrNotSupported is returned when requested metrics are not available in chosen Cinder API version
+var ErrNotSupported = errors.New("Not supported by Cinder API version")
+
+const (
+	identityV2 = "v2.0"
+	identityV3 = "v3.0"
+)
+
+// identityVersions lists recognized Keystone API versions, the same as used for authentication
+var identityVersions = []*utils.Version{
+	{ID: identityV2, Priority: 20, Suffix: "/v2.0/"},
+	{ID: identityV3, Priority: 30, Suffix: "/v3/"},
+}
+
 var apiPriority = map[string]int{
 	"v1.0": 1,
 	"v2.0": 2,
+	"v3.0": 3,
+}
+
+// blockStorageTypes lists types of Cinder endpoints, which can be used to retrieve API versions, starting from the newest
+var blockStorageTypes = []string{"volumev3", "volumev2", "volume"}
+
+// AuthOptions holds Keystone endpoint and credentials used for authentication
+// DomainName or DomainID selects domain of both user and projects, UserDomain and ProjectDomain options select them separately
+// Application credential (ID, or Name along with User) and its secret are used instead of User and Password when set
+// Pre-issued Token, or token read from TokenFile, is used instead of any credentials when set
+// CAFile, CertFile, KeyFile and Insecure configure TLS of requests sent to Keystone and all services
+type AuthOptions struct {
+	Endpoint                    string
+	User                        string
+	Password                    string
+	DomainName                  string
+	DomainID                    string
+	UserDomainName              string
+	UserDomainID                string
+	ProjectDomainName           string
+	ProjectDomainID             string
+	ApplicationCredentialID     string
+	ApplicationCredentialName   string
+	ApplicationCredentialSecret string
+	Token                       string
+	TokenFile                   string
+	CAFile                      string
+	CertFile                    string
+	KeyFile                     string
+	Insecure                    bool
+}
+
+// UsesApplicationCredential checks whether application credential is used instead of password
+func (opts AuthOptions) UsesApplicationCredential() bool {
+	return opts.ApplicationCredentialID != "" || opts.ApplicationCredentialName != ""
+}
+
+// UsesToken checks whether pre-issued token is used instead of credentials
+func (opts AuthOptions) UsesToken() bool {
+	return opts.Token != "" || opts.TokenFile != ""
+}
+
+// userDomain returns name and ID of user domain, domain of both user and projects is used when user domain is not set
+func (opts AuthOptions) userDomain() (string, string) {
+	if opts.UserDomainName != "" || opts.UserDomainID != "" {
+		return opts.UserDomainName, opts.UserDomainID
+	}
+	return opts.DomainName, opts.DomainID
+}
+
+// projectDomain returns name and ID of projects domain, domain of both user and projects is used when project domain is not set
+func (opts AuthOptions) projectDomain() (string, string) {
+	if opts.ProjectDomainName != "" || opts.ProjectDomainID != "" {
+		return opts.ProjectDomainName, opts.ProjectDomainID
+	}
+	return opts.DomainName, opts.DomainID
+}
+
+// separateDomains checks whether user domain or project domain is set
+func (opts AuthOptions) separateDomains() bool {
+	return opts.UserDomainName != "" || opts.UserDomainID != "" || opts.ProjectDomainName != "" || opts.ProjectDomainID != ""
+}
+
+// checkDomains returns error for conflicting domains instead of dropping them
+// Each domain is given either by name or by ID, domain of both user and projects cannot be combined with separate domains
+func (opts AuthOptions) checkDomains() error {
+	if opts.DomainName != "" && opts.DomainID != "" {
+		return errors.New("Domain name and domain ID are mutually exclusive")
+	}
+	if opts.UserDomainName != "" && opts.UserDomainID != "" {
+		return errors.New("User domain name and user domain ID are mutually exclusive")
+	}
+	if opts.ProjectDomainName != "" && opts.ProjectDomainID != "" {
+		return errors.New("Project domain name and project domain ID are mutually exclusive")
+	}
+	if (opts.DomainName != "" || opts.DomainID != "") && opts.separateDomains() {
+		return errors.New("Domain cannot be combined with user domain or project domain")
+	}
+	return nil
 }
 
 // Commoner provides abstraction for shared functions mainly for mocking
 type Commoner interface {
-	GetTenants(endpoint, user, password, domain_name, domain_id string) (map[string]string, error)
-	GetApiVersions(provider *gophercloud.ProviderClient) ([]string, error)
+	GetTenants(opts AuthOptions, tenant string) (map[string]string, error)
+	GetApiVersions(provider *gophercloud.ProviderClient, eo gophercloud.EndpointOpts) ([]string, error)
+	GetApiVersionsDetails(provider *gophercloud.ProviderClient, eo gophercloud.EndpointOpts) ([]apiversionsintel.APIVersion, error)
 }
 
 // Common is a receiver for Commoner interface
@@ -44,14 +144,35 @@ type Common struct{}
 
 // GetTenants is used to retrieve list of available tenant for authenticated user
 // List of tenants can then be used to authenticate user for each given tenant
-func (c Common) GetTenants(endpoint, user, password, domain_name, domain_id string) (map[string]string, error) {
-	tnts := map[string]string{}
+// Token is scoped to given tenant, as Keystone policy does not allow unscoped token to list projects and domains
+// Keystone API version is chosen based on endpoint, projects are listed when Keystone v3 API is used
+func (c Common) GetTenants(opts AuthOptions, tenant string) (map[string]string, error) {
+	provider, err := Authenticate(opts, tenant)
+	if err != nil {
+		return nil, err
+	}
 
-	provider, err := Authenticate(endpoint, user, password, "", domain_name, domain_id)
+	version, identityEndpoint, err := utils.ChooseVersion(provider, identityVersions)
 	if err != nil {
 		return nil, err
 	}
 
+	if version.ID == identityV3 {
+		client := openstack.NewIdentityV3(provider)
+		if identityEndpoint != "" {
+			client.Endpoint = identityEndpoint
+		}
+		domainName, domainID := opts.projectDomain()
+		return getProjects(client, domainName, domainID)
+	}
+
+	return getTenantsV2(provider)
+}
+
+// getTenantsV2 retrieves list of tenants using Keystone v2 API
+func getTenantsV2(provider *gophercloud.ProviderClient) (map[string]string, error) {
+	tnts := map[string]string{}
+
 	client := openstack.NewIdentityV2(provider)
 
 	opts := tenants.ListOpts{}
@@ -74,24 +195,120 @@ func (c Common) GetTenants(endpoint, user, password, domain_name, domain_id stri
 	return tnts, nil
 }
 
-// GetApiVersions is used to retrieve list of available Cinder API versions
-// List of api version is then used to dispatch calls to proper API version based on defined priority
-func (c Common) GetApiVersions(provider *gophercloud.ProviderClient) ([]string, error) {
-	apis := []string{}
+// getProjects retrieves list of projects using Keystone v3 API, optionally limited to given domain
+// Projects available for authenticated user are listed if user is not allowed to list all projects
+// It returns error if projects of different domains have the same name
+func getProjects(client *gophercloud.ServiceClient, domain_name, domain_id string) (map[string]string, error) {
+	tnts := map[string]string{}
 
-	client, err := openstackintel.NewBlockStorageV2(provider, gophercloud.EndpointOpts{})
+	// domain name is resolved only if user is allowed to list domains, projects are filtered by name of their domain otherwise
+	if domain_id == "" && domain_name != "" {
+		var err error
+		domain_id, err = getDomainID(client, domain_name)
+		if err != nil && !isForbidden(err) {
+			return nil, err
+		}
+	}
 
+	page, err := projectsintel.List(client, projectsintel.ListOpts{DomainID: domain_id}).AllPages()
+	if isForbidden(err) {
+		page, err = projectsintel.ListAvailable(client).AllPages()
+	}
 	if err != nil {
-		return apis, err
+		return tnts, err
 	}
 
-	page := apiversionsintel.Get(client)
-	if page.Err != nil {
-		fmt.Println("err ", err)
-		return apis, err
+	projectList, err := projectsintel.ExtractProjects(page)
+	if err != nil {
+		return tnts, err
 	}
 
-	apiVersions, err := apiversions.ExtractAPIVersions(page)
+	// tenants are identified by name in metric namespaces, so projects of different domains cannot share name
+	ids := map[string]string{}
+	domainNames := map[string]string{}
+	for _, p := range projectList {
+		// projects available for user are not filtered by Keystone
+		if domain_id != "" && p.DomainID != domain_id {
+			continue
+		}
+		if domain_id == "" && domain_name != "" {
+			name, err := getDomainName(client, p.DomainID, domainNames)
+			if err != nil {
+				return nil, err
+			}
+			if name != domain_name {
+				continue
+			}
+		}
+		if id, found := ids[p.Name]; found && id != p.ID {
+			return nil, fmt.Errorf("Projects %s and %s have the same name %s, set domain to list projects of single domain", id, p.ID, p.Name)
+		}
+		ids[p.Name] = p.ID
+		tnts[p.ID] = p.Name
+	}
+
+	return tnts, nil
+}
+
+// getDomainID resolves ID of domain with given name, listing domains requires administrative privileges
+func getDomainID(client *gophercloud.ServiceClient, domain_name string) (string, error) {
+	page, err := domainsintel.List(client, domainsintel.ListOpts{Name: domain_name}).AllPages()
+	if err != nil {
+		return "", err
+	}
+
+	domainList, err := domainsintel.ExtractDomains(page)
+	if err != nil {
+		return "", err
+	}
+
+	for _, d := range domainList {
+		if d.Name == domain_name {
+			return d.ID, nil
+		}
+	}
+
+	return "", fmt.Errorf("Domain %s not found", domain_name)
+}
+
+// getDomainName resolves name of domain with given ID, names already resolved are kept in given map
+// Domain which user is not allowed to read has empty name, token can read domain of project it is scoped to
+func getDomainName(client *gophercloud.ServiceClient, domain_id string, names map[string]string) (string, error) {
+	if name, found := names[domain_id]; found {
+		return name, nil
+	}
+
+	domain, err := domainsintel.Get(client, domain_id).Extract()
+	if err != nil && !isForbidden(err) {
+		return "", err
+	}
+	names[domain_id] = domain.Name
+
+	return domain.Name, nil
+}
+
+// isForbidden checks whether request was rejected due to insufficient privileges
+func isForbidden(err error) bool {
+	if e, ok := err.(*gophercloud.UnexpectedResponseCodeError); ok {
+		return e.Actual == http.StatusForbidden
+	}
+	return false
+}
+
+// IsUnavailable checks whether request was rejected due to insufficient privileges or missing API extension
+func IsUnavailable(err error) bool {
+	if e, ok := err.(*gophercloud.UnexpectedResponseCodeError); ok {
+		return e.Actual == http.StatusForbidden || e.Actual == http.StatusNotFound
+	}
+	return false
+}
+
+// GetApiVersions is used to retrieve list of available Cinder API versions
+// List of api version is then used to dispatch calls to proper API version based on defined priority
+func (c Common) GetApiVersions(provider *gophercloud.ProviderClient, eo gophercloud.EndpointOpts) ([]string, error) {
+	apis := []string{}
+
+	apiVersions, err := c.GetApiVersionsDetails(provider, eo)
 	if err != nil {
 		return apis, err
 	}
@@ -103,31 +320,189 @@ func (c Common) GetApiVersions(provider *gophercloud.ProviderClient) ([]string,
 	return apis, nil
 }
 
+// GetApiVersionsDetails is used to retrieve list of available Cinder API versions along with supported microversions
+// Versions are retrieved from any Cinder endpoint of given region and interface found in service catalog
+func (c Common) GetApiVersionsDetails(provider *gophercloud.ProviderClient, eo gophercloud.EndpointOpts) ([]apiversionsintel.APIVersion, error) {
+	var client *gophercloud.ServiceClient
+	var err error
+	for _, endpointType := range blockStorageTypes {
+		eo.Type = endpointType
+		client, err = openstackintel.NewBlockStorageV2(provider, eo)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	page := apiversionsintel.Get(client)
+	if page.Err != nil {
+		return nil, page.Err
+	}
+
+	return apiversionsintel.ExtractAPIVersions(page)
+}
+
 // Authenticate is used to authenticate user for given tenant. Request is send to provided Keystone endpoint
 // Returns authenticated provider client, which is used as a base for service clients.
-func Authenticate(endpoint, user, password, tenant, domain_name, domain_id string) (*gophercloud.ProviderClient, error) {
+func Authenticate(opts AuthOptions, tenant string) (*gophercloud.ProviderClient, error) {
+	if opts.UsesToken() {
+		return authenticateToken(opts, tenant)
+	}
+	if err := opts.checkDomains(); err != nil {
+		return nil, err
+	}
+	if opts.UsesApplicationCredential() {
+		return authenticateApplicationCredential(opts, tenant)
+	}
+	if opts.separateDomains() {
+		return authenticatePassword(opts, tenant)
+	}
+
 	authOpts := gophercloud.AuthOptions{
-		IdentityEndpoint: endpoint,
-		Username:         user,
-		Password:         password,
+		IdentityEndpoint: opts.Endpoint,
+		Username:         opts.User,
+		Password:         opts.Password,
 		TenantName:       tenant,
+		DomainName:       opts.DomainName,
+		DomainID:         opts.DomainID,
 		AllowReauth:      true,
 	}
-	if domain_name != "" && domain_id == "" {
-		authOpts.DomainName = domain_name
+
+	provider, err := newClient(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := openstack.Authenticate(provider, authOpts); err != nil {
+		return nil, err
 	}
-	if domain_id != "" && domain_name == "" {
-		authOpts.DomainID = domain_id
+
+	return provider, nil
+}
+
+// authenticateApplicationCredential issues token for application credential using Keystone v3 API
+// Application credential is always scoped to project it was created for, so error is returned when other tenant is requested
+// Token is issued again when it expires
+func authenticateApplicationCredential(opts AuthOptions, tenant string) (*gophercloud.ProviderClient, error) {
+	client, err := newIdentityV3(opts)
+	if err != nil {
+		return nil, err
 	}
 
-	provider, err := openstack.AuthenticatedClient(authOpts)
+	provider, err := newClient(opts)
 	if err != nil {
 		return nil, err
 	}
 
+	domainName, domainID := opts.userDomain()
+	credential := tokensintel.ApplicationCredentialOpts{
+		ID:         opts.ApplicationCredentialID,
+		Name:       opts.ApplicationCredentialName,
+		Secret:     opts.ApplicationCredentialSecret,
+		UserName:   opts.User,
+		DomainName: domainName,
+		DomainID:   domainID,
+	}
+	auth := func() error {
+		return useToken(provider, tokensintel.Create(client, credential), tenant)
+	}
+
+	if err := auth(); err != nil {
+		return nil, err
+	}
+	provider.ReauthFunc = auth
+
 	return provider, nil
 }
 
+// authenticatePassword issues token for user of user domain scoped to tenant of project domain using Keystone v3 API
+// It is used when user and projects live in different domains, token is issued again when it expires
+func authenticatePassword(opts AuthOptions, tenant string) (*gophercloud.ProviderClient, error) {
+	client, err := newIdentityV3(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := newClient(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	userDomainName, userDomainID := opts.userDomain()
+	projectDomainName, projectDomainID := opts.projectDomain()
+	password := tokensintel.PasswordOpts{
+		UserName:          opts.User,
+		Password:          opts.Password,
+		UserDomainName:    userDomainName,
+		UserDomainID:      userDomainID,
+		ProjectName:       tenant,
+		ProjectDomainName: projectDomainName,
+		ProjectDomainID:   projectDomainID,
+	}
+	auth := func() error {
+		return useToken(provider, tokensintel.Create(client, password), tenant)
+	}
+
+	if err := auth(); err != nil {
+		return nil, err
+	}
+	provider.ReauthFunc = auth
+
+	return provider, nil
+}
+
+// newIdentityV3 creates Keystone v3 API client used to issue and validate tokens
+// Client is based on separate provider, so that failed authentication does not trigger reauthentication
+func newIdentityV3(opts AuthOptions) (*gophercloud.ServiceClient, error) {
+	identity, err := newClient(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	version, identityEndpoint, err := utils.ChooseVersion(identity, identityVersions)
+	if err != nil {
+		return nil, err
+	}
+	if version.ID != identityV3 {
+		return nil, fmt.Errorf("Keystone v3 API is required, found %s", version.ID)
+	}
+
+	client := openstack.NewIdentityV3(identity)
+	if identityEndpoint != "" {
+		client.Endpoint = identityEndpoint
+	}
+	return client, nil
+}
+
+// tokenResult is implemented by results of Keystone v3 token requests
+type tokenResult interface {
+	ExtractToken() (*tokensintel.Token, error)
+	ExtractServiceCatalog() (*tokens3.ServiceCatalog, error)
+}
+
+// useToken sets token and service catalog of token request result in provider
+// Token has to be scoped to given tenant unless tenant is empty
+func useToken(provider *gophercloud.ProviderClient, result tokenResult, tenant string) error {
+	token, err := result.ExtractToken()
+	if err != nil {
+		return err
+	}
+	if tenant != "" && token.Project.Name != tenant {
+		return fmt.Errorf("Token is scoped to project %s, cannot authenticate for tenant %s", token.Project.Name, tenant)
+	}
+	catalog, err := result.ExtractServiceCatalog()
+	if err != nil {
+		return err
+	}
+
+	provider.TokenID = token.ID
+	provider.EndpointLocator = func(eo gophercloud.EndpointOpts) (string, error) {
+		return openstack.V3EndpointURL(catalog, eo)
+	}
+	return nil
+}
+
 // ChooseVersion returns chosen Cinder API version based on defined priority
 func ChooseVersion(recognized []string) (string, error) {
 	if len(recognized) < 1 {
@@ -17,15 +17,22 @@ limitations under the License.
 package openstack
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/rackspace/gophercloud"
 	th "github.com/rackspace/gophercloud/testhelper"
 )
 
@@ -36,6 +43,16 @@ type CommonSuite struct {
 	V1, V2                   string
 	Tenant1ID, Tenant2ID     string
 	Tenant1Name, Tenant2Name string
+	DomainID, DomainName     string
+	CustomerDomainID         string
+	Forbidden                int32
+	Unscoped                 int32
+	AvailableListed          int32
+	DuplicateName            int32
+	AppCredentialID          string
+	AppCredentialSecret      string
+	RotatedToken             string
+	SeenToken                atomic.Value
 }
 
 func (s *CommonSuite) SetupSuite() {
@@ -47,6 +64,16 @@ func (s *CommonSuite) SetupSuite() {
 	s.Tenant1ID = "3e3e3e"
 	s.Tenant2ID = "4f4f4f"
 	registerTenants(s)
+	s.DomainID = "default_id"
+	s.DomainName = "Default"
+	s.CustomerDomainID = "customer_id"
+	s.AppCredentialID = "appcred123"
+	s.AppCredentialSecret = "s3cret"
+	s.RotatedToken = "5fe8a5c5d0ac4d3a8c2ad27b4a6b2e11"
+	registerAuthenticationV3(s)
+	registerTokenCheck(s)
+	registerProjects(s)
+	registerDomains(s)
 }
 
 func (s *CommonSuite) TearDownSuite() {
@@ -57,7 +84,7 @@ func (s *CommonSuite) TestGetTenants() {
 	Convey("Given tenants are requested", s.T(), func() {
 		c := Common{}
 		Convey("When Gettenants is called", func() {
-			tenants, err := c.GetTenants(th.Endpoint(), "me", "secret", "", "")
+			tenants, err := c.GetTenants(AuthOptions{Endpoint: th.Endpoint(), User: "me", Password: "secret"}, s.Tenant1Name)
 
 			Convey("Then list of available tenats is returned", func() {
 				So(len(tenants), ShouldEqual, 2)
@@ -69,11 +96,273 @@ func (s *CommonSuite) TestGetTenants() {
 	})
 }
 
+func (s *CommonSuite) TestGetProjects() {
+	Convey("Given projects are requested from Keystone v3 API", s.T(), func() {
+		c := Common{}
+
+		Convey("When GetTenants is called with domain ID", func() {
+			atomic.StoreInt32(&s.AvailableListed, 0)
+			tenants, err := c.GetTenants(AuthOptions{Endpoint: th.Endpoint() + "v3/", User: "me", Password: "secret", DomainID: s.DomainID}, s.Tenant1Name)
+
+			Convey("Then projects of given domain are returned", func() {
+				So(err, ShouldBeNil)
+				So(len(tenants), ShouldEqual, 2)
+				So(tenants[s.Tenant1ID], ShouldEqual, s.Tenant1Name)
+				So(tenants[s.Tenant2ID], ShouldEqual, s.Tenant2Name)
+			})
+
+			Convey("and all projects are listed with token scoped to given tenant", func() {
+				So(atomic.LoadInt32(&s.Unscoped), ShouldEqual, 0)
+				So(atomic.LoadInt32(&s.AvailableListed), ShouldEqual, 0)
+			})
+		})
+
+		Convey("When GetTenants is called with domain name", func() {
+			tenants, err := c.GetTenants(AuthOptions{Endpoint: th.Endpoint() + "v3/", User: "me", Password: "secret", DomainName: s.DomainName}, s.Tenant1Name)
+
+			Convey("Then projects of given domain are returned", func() {
+				So(err, ShouldBeNil)
+				So(len(tenants), ShouldEqual, 2)
+				So(tenants[s.Tenant1ID], ShouldEqual, s.Tenant1Name)
+			})
+		})
+
+		Convey("When user is not allowed to list all projects", func() {
+			atomic.StoreInt32(&s.Forbidden, 1)
+			tenants, err := c.GetTenants(AuthOptions{Endpoint: th.Endpoint() + "v3/", User: "me", Password: "secret", DomainName: s.DomainName}, s.Tenant1Name)
+			atomic.StoreInt32(&s.Forbidden, 0)
+
+			Convey("Then projects available for user are filtered by name of their domain", func() {
+				So(err, ShouldBeNil)
+				So(len(tenants), ShouldEqual, 2)
+				So(tenants[s.Tenant2ID], ShouldEqual, s.Tenant2Name)
+			})
+		})
+
+		Convey("When domain does not exist", func() {
+			_, err := c.GetTenants(AuthOptions{Endpoint: th.Endpoint() + "v3/", User: "me", Password: "secret", DomainName: "Unknown"}, s.Tenant1Name)
+
+			Convey("Then error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When projects of different domains have the same name", func() {
+			atomic.StoreInt32(&s.DuplicateName, 1)
+			_, err := c.GetTenants(AuthOptions{Endpoint: th.Endpoint() + "v3/", User: "me", Password: "secret", UserDomainName: s.DomainName}, "")
+			atomic.StoreInt32(&s.DuplicateName, 0)
+
+			Convey("Then error is returned instead of merging projects", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "the same name "+s.Tenant2Name)
+			})
+		})
+
+		Convey("When token is not scoped", func() {
+			atomic.StoreInt32(&s.AvailableListed, 0)
+			_, err := c.GetTenants(AuthOptions{Endpoint: th.Endpoint() + "v3/", User: "me", Password: "secret", DomainID: s.DomainID}, "")
+
+			Convey("Then only projects available for user are listed", func() {
+				So(err, ShouldBeNil)
+				So(atomic.LoadInt32(&s.AvailableListed), ShouldEqual, 1)
+			})
+		})
+	})
+}
+
+func (s *CommonSuite) TestAuthenticateApplicationCredential() {
+	Convey("Given application credential is used for authentication", s.T(), func() {
+		opts := AuthOptions{
+			Endpoint:                    th.Endpoint() + "v3/",
+			ApplicationCredentialID:     s.AppCredentialID,
+			ApplicationCredentialSecret: s.AppCredentialSecret,
+		}
+
+		Convey("When tenant of application credential is requested", func() {
+			provider, err := Authenticate(opts, s.Tenant1Name)
+
+			Convey("Then token is issued without password", func() {
+				So(err, ShouldBeNil)
+				So(provider.TokenID, ShouldEqual, s.Token)
+				So(provider.ReauthFunc, ShouldNotBeNil)
+			})
+
+			Convey("and endpoints are located in service catalog of token", func() {
+				url, err := provider.EndpointLocator(gophercloud.EndpointOpts{Type: "volumev2", Availability: gophercloud.AvailabilityPublic})
+				So(err, ShouldBeNil)
+				So(url, ShouldEqual, th.Endpoint()+s.V2)
+			})
+		})
+
+		Convey("When other tenant is requested", func() {
+			_, err := Authenticate(opts, s.Tenant2Name)
+
+			Convey("Then error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When secret is incorrect", func() {
+			opts.ApplicationCredentialSecret = "wrong"
+			_, err := Authenticate(opts, "")
+
+			Convey("Then error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When tenants are requested", func() {
+			opts.DomainID = s.DomainID
+			tenants, err := Common{}.GetTenants(opts, s.Tenant1Name)
+
+			Convey("Then projects are listed with application credential token", func() {
+				So(err, ShouldBeNil)
+				So(len(tenants), ShouldEqual, 2)
+			})
+		})
+	})
+}
+
+func (s *CommonSuite) TestAuthenticateSeparateDomains() {
+	Convey("Given user and projects in different domains", s.T(), func() {
+		opts := AuthOptions{
+			Endpoint:        th.Endpoint() + "v3/",
+			User:            "me",
+			Password:        "secret",
+			UserDomainName:  s.DomainName,
+			ProjectDomainID: s.CustomerDomainID,
+		}
+
+		Convey("When tenant of project domain is requested", func() {
+			provider, err := Authenticate(opts, s.Tenant2Name)
+
+			Convey("Then token is scoped to tenant using both domains", func() {
+				So(err, ShouldBeNil)
+				So(provider.TokenID, ShouldEqual, s.Token)
+				So(provider.ReauthFunc, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When tenant of other domain is requested", func() {
+			opts.ProjectDomainID = "other_id"
+			_, err := Authenticate(opts, s.Tenant2Name)
+
+			Convey("Then error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When tenants are requested", func() {
+			opts.ProjectDomainID = s.DomainID
+			tenants, err := Common{}.GetTenants(opts, s.Tenant1Name)
+
+			Convey("Then projects of project domain are listed", func() {
+				So(err, ShouldBeNil)
+				So(len(tenants), ShouldEqual, 2)
+			})
+		})
+
+		Convey("When domain is combined with project domain", func() {
+			opts.UserDomainName = ""
+			opts.DomainName = s.DomainName
+			_, err := Authenticate(opts, s.Tenant2Name)
+
+			Convey("Then error is returned instead of dropping domains", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When both name and ID of user domain are given", func() {
+			opts.UserDomainID = s.DomainID
+			_, err := Authenticate(opts, s.Tenant2Name)
+
+			Convey("Then error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When both name and ID of domain are given", func() {
+			_, err := Authenticate(AuthOptions{Endpoint: th.Endpoint() + "v3/", User: "me", Password: "secret", DomainName: s.DomainName, DomainID: s.DomainID}, "")
+
+			Convey("Then error is returned instead of dropping domain", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+func (s *CommonSuite) TestAuthenticateToken() {
+	Convey("Given pre-issued token is used for authentication", s.T(), func() {
+		opts := AuthOptions{Endpoint: th.Endpoint() + "v3/", Token: s.Token}
+
+		Convey("When token is given directly", func() {
+			provider, err := Authenticate(opts, s.Tenant1Name)
+
+			Convey("Then token is used without password flow", func() {
+				So(err, ShouldBeNil)
+				So(provider.TokenID, ShouldEqual, s.Token)
+			})
+
+			Convey("and endpoints are located in service catalog of token", func() {
+				url, err := provider.EndpointLocator(gophercloud.EndpointOpts{Type: "volumev2", Availability: gophercloud.AvailabilityPublic})
+				So(err, ShouldBeNil)
+				So(url, ShouldEqual, th.Endpoint()+s.V2)
+			})
+		})
+
+		Convey("When token is scoped to other tenant", func() {
+			_, err := Authenticate(opts, s.Tenant2Name)
+
+			Convey("Then error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When token is invalid", func() {
+			opts.Token = "invalid"
+			_, err := Authenticate(opts, "")
+
+			Convey("Then error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When token is read from file", func() {
+			file, err := ioutil.TempFile("", "token")
+			So(err, ShouldBeNil)
+			defer os.Remove(file.Name())
+			So(ioutil.WriteFile(file.Name(), []byte(s.Token+"\n"), 0600), ShouldBeNil)
+
+			provider, err := Authenticate(AuthOptions{Endpoint: th.Endpoint() + "v3/", TokenFile: file.Name()}, "")
+			So(err, ShouldBeNil)
+			So(provider.TokenID, ShouldEqual, s.Token)
+
+			Convey("and token file changes", func() {
+				So(ioutil.WriteFile(file.Name(), []byte(s.RotatedToken), 0600), ShouldBeNil)
+				later := time.Now().Add(time.Minute)
+				So(os.Chtimes(file.Name(), later, later), ShouldBeNil)
+
+				_, err := provider.Request("GET", th.Endpoint()+"tokencheck", gophercloud.RequestOpts{OkCodes: []int{200}})
+
+				Convey("Then changed token is sent with following requests", func() {
+					So(err, ShouldBeNil)
+					So(s.SeenToken.Load(), ShouldEqual, s.RotatedToken)
+				})
+
+				Convey("and changed token is used after reauthentication", func() {
+					So(provider.ReauthFunc(), ShouldBeNil)
+					So(provider.TokenID, ShouldEqual, s.RotatedToken)
+				})
+			})
+		})
+	})
+}
+
 func (s *CommonSuite) TestGetAPI() {
 	Convey("Given api versions are requested", s.T(), func() {
 		c := Common{}
 		Convey("When GetAPIVersions is called", func() {
-			provider, err := Authenticate(th.Endpoint(), "me", "secret", "tenant", "", "")
+			provider, err := Authenticate(AuthOptions{Endpoint: th.Endpoint(), User: "me", Password: "secret"}, "tenant")
 			th.AssertNoErr(s.T(), err)
 			th.CheckEquals(s.T(), s.Token, provider.TokenID)
 
@@ -119,7 +408,7 @@ func (s *CommonSuite) TestGetAPI() {
 
 			httpClient := http.Client{Transport: transport}
 			provider.HTTPClient = httpClient
-			apis, err := c.GetApiVersions(provider)
+			apis, err := c.GetApiVersions(provider, gophercloud.EndpointOpts{})
 
 			Convey("Then list of available versions is returned", func() {
 				So(len(apis), ShouldEqual, 2)
@@ -261,6 +550,229 @@ func registerTenants(s *CommonSuite) {
 	})
 }
 
+// tokenBody is Keystone v3 token response with catalog containing Cinder endpoint and token scope
+const tokenBody = `
+		{
+			"token": {
+				"catalog": [
+					{
+						"endpoints": [
+							{
+								"id": "3ffe125aa59547029ed774c10b932349",
+								"interface": "public",
+								"region": "RegionOne",
+								"url": "%s"
+							}
+						],
+						"id": "4f4f4f",
+						"name": "cinderv2",
+						"type": "volumev2"
+					}
+				],
+				"expires_at": "2026-02-21T14:28:30.000000Z",
+				"issued_at": "2026-02-21T13:28:30.000000Z",
+				"methods": ["password"],
+				"project": %s
+			}
+		}
+	`
+
+func registerAuthenticationV3(s *CommonSuite) {
+	th.Mux.HandleFunc("/v3/auth/tokens", func(w http.ResponseWriter, r *http.Request) {
+		// pre-issued tokens are validated with themselves, validated tokens are scoped to project
+		if r.Method == "GET" {
+			token := r.Header.Get("X-Subject-Token")
+			if token != r.Header.Get("X-Auth-Token") || (token != s.Token && token != s.RotatedToken) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			atomic.StoreInt32(&s.Unscoped, 0)
+			w.Header().Add("X-Subject-Token", token)
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintf(w, tokenBody, th.Endpoint()+s.V2, fmt.Sprintf(`{"id": "%s", "name": "%s"}`, s.Tenant1ID, s.Tenant1Name))
+			return
+		}
+		th.TestMethod(s.T(), r, "POST")
+
+		type domain struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		}
+		var req struct {
+			Auth struct {
+				Identity struct {
+					Methods               []string `json:"methods"`
+					ApplicationCredential struct {
+						ID     string `json:"id"`
+						Secret string `json:"secret"`
+					} `json:"application_credential"`
+					Password struct {
+						User struct {
+							Name   string `json:"name"`
+							Domain domain `json:"domain"`
+						} `json:"user"`
+					} `json:"password"`
+				} `json:"identity"`
+				Scope struct {
+					Project struct {
+						Name   string `json:"name"`
+						Domain domain `json:"domain"`
+					} `json:"project"`
+				} `json:"scope"`
+			} `json:"auth"`
+		}
+		th.AssertNoErr(s.T(), json.NewDecoder(r.Body).Decode(&req))
+
+		// application credential tokens are always scoped to project of credential
+		project := "null"
+		if len(req.Auth.Identity.Methods) > 0 && req.Auth.Identity.Methods[0] == "application_credential" {
+			credential := req.Auth.Identity.ApplicationCredential
+			if credential.ID != s.AppCredentialID || credential.Secret != s.AppCredentialSecret {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			project = fmt.Sprintf(`{"id": "%s", "name": "%s"}`, s.Tenant1ID, s.Tenant1Name)
+		}
+
+		// projects of default and customer domain are available for users of default domain
+		if scope := req.Auth.Scope.Project; scope.Name != "" {
+			user := req.Auth.Identity.Password.User.Domain
+			if (user.Name != s.DomainName && user.ID != s.DomainID) ||
+				(scope.Domain.Name != s.DomainName && scope.Domain.ID != s.DomainID && scope.Domain.ID != s.CustomerDomainID) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			id := s.Tenant2ID
+			if scope.Name == s.Tenant1Name {
+				id = s.Tenant1ID
+			}
+			project = fmt.Sprintf(`{"id": "%s", "name": "%s"}`, id, scope.Name)
+		}
+
+		// Keystone policy rejects listing projects and domains with unscoped token
+		if project == "null" {
+			atomic.StoreInt32(&s.Unscoped, 1)
+		} else {
+			atomic.StoreInt32(&s.Unscoped, 0)
+		}
+
+		w.Header().Add("X-Subject-Token", s.Token)
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+
+		fmt.Fprintf(w, tokenBody, th.Endpoint()+s.V2, project)
+	})
+}
+
+func registerTokenCheck(s *CommonSuite) {
+	th.Mux.HandleFunc("/tokencheck", func(w http.ResponseWriter, r *http.Request) {
+		s.SeenToken.Store(r.Header.Get("X-Auth-Token"))
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func registerProjects(s *CommonSuite) {
+	projects := `
+		{
+			"links": {
+				"next": null,
+				"previous": null,
+				"self": "%s"
+			},
+			"projects": [
+				%s
+			]
+		}
+	`
+	project := `{"domain_id": "%s", "enabled": true, "id": "%s", "name": "%s"}`
+	domainProjects := []string{
+		fmt.Sprintf(project, s.DomainID, s.Tenant1ID, s.Tenant1Name),
+		fmt.Sprintf(project, s.DomainID, s.Tenant2ID, s.Tenant2Name),
+	}
+
+	th.Mux.HandleFunc("/v3/projects", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(s.T(), r, "GET")
+		th.TestHeader(s.T(), r, "X-Auth-Token", s.Token)
+
+		if atomic.LoadInt32(&s.Forbidden) == 1 || atomic.LoadInt32(&s.Unscoped) == 1 {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		th.TestFormValues(s.T(), r, map[string]string{"domain_id": s.DomainID})
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		fmt.Fprintf(w, projects, th.Endpoint()+"v3/projects", domainProjects[0]+","+domainProjects[1])
+	})
+
+	th.Mux.HandleFunc("/v3/auth/projects", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(s.T(), r, "GET")
+		th.TestHeader(s.T(), r, "X-Auth-Token", s.Token)
+		atomic.AddInt32(&s.AvailableListed, 1)
+
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		// project of other domain may have the same name as project of default domain
+		otherName := "other"
+		if atomic.LoadInt32(&s.DuplicateName) == 1 {
+			otherName = s.Tenant2Name
+		}
+		otherProject := fmt.Sprintf(project, "other_id", "5a5a5a", otherName)
+		fmt.Fprintf(w, projects, th.Endpoint()+"v3/auth/projects", domainProjects[0]+","+domainProjects[1]+","+otherProject)
+	})
+}
+
+func registerDomains(s *CommonSuite) {
+	domains := `
+		{
+			"links": {
+				"next": null,
+				"previous": null,
+				"self": "%s"
+			},
+			"domains": [
+				%s
+			]
+		}
+	`
+	domain := fmt.Sprintf(`{"enabled": true, "id": "%s", "name": "%s"}`, s.DomainID, s.DomainName)
+
+	th.Mux.HandleFunc("/v3/domains", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(s.T(), r, "GET")
+		th.TestHeader(s.T(), r, "X-Auth-Token", s.Token)
+
+		if atomic.LoadInt32(&s.Forbidden) == 1 || atomic.LoadInt32(&s.Unscoped) == 1 {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		found := ""
+		if r.URL.Query().Get("name") == s.DomainName {
+			found = domain
+		}
+		fmt.Fprintf(w, domains, th.Endpoint()+"v3/domains", found)
+	})
+
+	// token scoped to project of default domain can read only default domain
+	th.Mux.HandleFunc("/v3/domains/", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(s.T(), r, "GET")
+		th.TestHeader(s.T(), r, "X-Auth-Token", s.Token)
+
+		if strings.TrimPrefix(r.URL.Path, "/v3/domains/") != s.DomainID {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		fmt.Fprintf(w, `{"domain": %s}`, domain)
+	})
+}
+
 func registerAPI(s *CommonSuite) {
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Add("Content-Type", "application/json")
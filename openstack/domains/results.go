@@ -0,0 +1,71 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// results contains Keystone v3 API responses and their processing for domains
+
+package domains
+
+import (
+	"github.com/mitchellh/mapstructure"
+	"github.com/rackspace/gophercloud"
+	"github.com/rackspace/gophercloud/pagination"
+)
+
+// Domain contains information associated with an OpenStack domain
+type Domain struct {
+	ID      string `mapstructure:"id"`
+	Name    string `mapstructure:"name"`
+	Enabled bool   `mapstructure:"enabled"`
+}
+
+// DomainPage is a single page of Domain results.
+type DomainPage struct {
+	pagination.LinkedPageBase
+}
+
+// IsEmpty determines whether or not a page of Domains contains any results.
+func (r DomainPage) IsEmpty() (bool, error) {
+	domains, err := ExtractDomains(r)
+	if err != nil {
+		return true, err
+	}
+	return len(domains) == 0, nil
+}
+
+// ExtractDomains extracts and returns Domains. It is used while iterating over a domains.List call.
+func ExtractDomains(page pagination.Page) ([]Domain, error) {
+	var response struct {
+		Domains []Domain `mapstructure:"domains"`
+	}
+	err := mapstructure.Decode(page.(DomainPage).Body, &response)
+	return response.Domains, err
+}
+
+// GetResult contains the response body and error from a Get request
+type GetResult struct {
+	gophercloud.Result
+}
+
+// Extract will get the domain out of the GetResult object
+func (r GetResult) Extract() (Domain, error) {
+	if r.Err != nil {
+		return Domain{}, r.Err
+	}
+
+	var response struct {
+		Domain Domain `mapstructure:"domain"`
+	}
+	err := mapstructure.Decode(r.Body, &response)
+	return response.Domain, err
+}
@@ -0,0 +1,68 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// results contains Cinder API responses and their processing for services
+
+package osservices
+
+import (
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/rackspace/gophercloud"
+)
+
+// updatedAtFormats lists time formats used by Cinder for updated_at field
+var updatedAtFormats = []string{"2006-01-02T15:04:05.000000", "2006-01-02T15:04:05", time.RFC3339}
+
+// ListResult contains the response body and error from a List request
+type ListResult struct {
+	gophercloud.Result
+}
+
+// Service contains information about Cinder service (e.g. cinder-volume, cinder-scheduler) running on host
+type Service struct {
+	Binary    string `mapstructure:"binary"`
+	Host      string `mapstructure:"host"`
+	Zone      string `mapstructure:"zone"`
+	State     string `mapstructure:"state"`
+	Status    string `mapstructure:"status"`
+	UpdatedAt string `mapstructure:"updated_at"`
+}
+
+// Extract will get the list of services out of the ListResult object
+func (r ListResult) Extract() ([]Service, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+
+	var res struct {
+		Services []Service `mapstructure:"services"`
+	}
+
+	err := mapstructure.Decode(r.Body, &res)
+	return res.Services, err
+}
+
+// SecondsSinceUpdate returns number of seconds elapsed since last service heartbeat
+// It returns -1 if service has never reported its state
+func (s Service) SecondsSinceUpdate(now time.Time) float64 {
+	for _, format := range updatedAtFormats {
+		updated, err := time.Parse(format, s.UpdatedAt)
+		if err == nil {
+			return now.Sub(updated).Seconds()
+		}
+	}
+	return -1
+}
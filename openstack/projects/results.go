@@ -0,0 +1,53 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// results contains Keystone v3 API responses and their processing for projects
+
+package projects
+
+import (
+	"github.com/mitchellh/mapstructure"
+	"github.com/rackspace/gophercloud/pagination"
+)
+
+// Project contains information associated with an OpenStack project
+type Project struct {
+	ID       string `mapstructure:"id"`
+	Name     string `mapstructure:"name"`
+	DomainID string `mapstructure:"domain_id"`
+	Enabled  bool   `mapstructure:"enabled"`
+}
+
+// ProjectPage is a single page of Project results.
+type ProjectPage struct {
+	pagination.LinkedPageBase
+}
+
+// IsEmpty determines whether or not a page of Projects contains any results.
+func (r ProjectPage) IsEmpty() (bool, error) {
+	projects, err := ExtractProjects(r)
+	if err != nil {
+		return true, err
+	}
+	return len(projects) == 0, nil
+}
+
+// ExtractProjects extracts and returns Projects. It is used while iterating over a projects.List call.
+func ExtractProjects(page pagination.Page) ([]Project, error) {
+	var response struct {
+		Projects []Project `mapstructure:"projects"`
+	}
+	err := mapstructure.Decode(page.(ProjectPage).Body, &response)
+	return response.Projects, err
+}
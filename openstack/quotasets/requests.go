@@ -0,0 +1,30 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// requests contains Cinder API requests for quota sets
+
+package quotasets
+
+import (
+	"github.com/rackspace/gophercloud"
+)
+
+// Get prepares http GET call on Cinder os-quota-sets endpoint for given project including quota usage.
+// Quotas of other projects can be read only with administrative privileges.
+func Get(client *gophercloud.ServiceClient, projectID string) GetResult {
+	var res GetResult
+	_, err := client.Get(client.ServiceURL("os-quota-sets", projectID)+"?usage=true", &res.Body, nil)
+	res.Err = err
+	return res
+}
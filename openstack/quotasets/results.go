@@ -0,0 +1,100 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// results contains Cinder API responses and their processing for quota sets
+
+package quotasets
+
+import (
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/rackspace/gophercloud"
+
+	"github.com/intelsdi-x/snap-plugin-collector-cinder/types"
+)
+
+// Quota contains limit, usage and reservations of single project quota
+type Quota struct {
+	Limit    int `mapstructure:"limit"`
+	InUse    int `mapstructure:"in_use"`
+	Reserved int `mapstructure:"reserved"`
+}
+
+// QuotaSet contains project quotas keyed by quota name (e.g. volumes, gigabytes, volumes_<volume type>)
+type QuotaSet map[string]Quota
+
+// VolumeTypeQuota contains quotas of single volume type
+type VolumeTypeQuota struct {
+	Volumes   Quota
+	Gigabytes Quota
+}
+
+// VolumeTypes returns quotas set separately for volume types, reported as volumes_<volume type> and gigabytes_<volume type>
+// Volume type names are sanitized with types.SanitizeName, as they are used in metric namespaces
+func (q QuotaSet) VolumeTypes() map[string]VolumeTypeQuota {
+	volumeTypes := map[string]VolumeTypeQuota{}
+	for name, quota := range q {
+		switch {
+		case strings.HasPrefix(name, "volumes_"):
+			volumeType := types.SanitizeName(strings.TrimPrefix(name, "volumes_"))
+			typeQuota := volumeTypes[volumeType]
+			typeQuota.Volumes = quota
+			volumeTypes[volumeType] = typeQuota
+		case strings.HasPrefix(name, "gigabytes_"):
+			volumeType := types.SanitizeName(strings.TrimPrefix(name, "gigabytes_"))
+			typeQuota := volumeTypes[volumeType]
+			typeQuota.Gigabytes = quota
+			volumeTypes[volumeType] = typeQuota
+		}
+	}
+	return volumeTypes
+}
+
+// GetResult contains the response body and error from a Get request
+type GetResult struct {
+	gophercloud.Result
+}
+
+// Extract will get the quota set out of the GetResult object
+// Only quotas reported with usage are extracted
+func (r GetResult) Extract() (QuotaSet, error) {
+	quotaSet := QuotaSet{}
+	if r.Err != nil {
+		return quotaSet, r.Err
+	}
+
+	var res struct {
+		QuotaSet map[string]interface{} `mapstructure:"quota_set"`
+	}
+
+	err := mapstructure.Decode(r.Body, &res)
+	if err != nil {
+		return quotaSet, err
+	}
+
+	for name, value := range res.QuotaSet {
+		if _, ok := value.(map[string]interface{}); !ok {
+			continue
+		}
+
+		quota := Quota{}
+		if err := mapstructure.Decode(value, &quota); err != nil {
+			return quotaSet, err
+		}
+		quotaSet[name] = quota
+	}
+
+	return quotaSet, nil
+}
@@ -17,19 +17,28 @@ limitations under the License.
 package services
 
 import (
+	"fmt"
+
 	"github.com/rackspace/gophercloud"
 
 	openstackintel "github.com/intelsdi-x/snap-plugin-collector-cinder/openstack"
+	apiversionsintel "github.com/intelsdi-x/snap-plugin-collector-cinder/openstack/apiversions"
 	cinderv1 "github.com/intelsdi-x/snap-plugin-collector-cinder/openstack/v1/cinder"
 	cinderv2 "github.com/intelsdi-x/snap-plugin-collector-cinder/openstack/v2/cinder"
+	openstackv3 "github.com/intelsdi-x/snap-plugin-collector-cinder/openstack/v3"
+	cinderv3 "github.com/intelsdi-x/snap-plugin-collector-cinder/openstack/v3/cinder"
 	"github.com/intelsdi-x/snap-plugin-collector-cinder/types"
 )
 
 // Cinderer allows usage of different Cinder API versions for metric collection
 type Cinderer interface {
-	GetLimits(provider *gophercloud.ProviderClient) (types.Limits, error)
-	GetVolumes(provider *gophercloud.ProviderClient) (map[string]types.Volumes, error)
-	GetSnapshots(provider *gophercloud.ProviderClient) (map[string]types.Snapshots, error)
+	GetLimits(provider *gophercloud.ProviderClient) (types.TenantLimits, error)
+	GetQuotas(provider *gophercloud.ProviderClient, tenantID string) (types.TenantLimits, error)
+	GetVolumes(provider *gophercloud.ProviderClient) (map[string]types.TenantVolumes, error)
+	GetSnapshots(provider *gophercloud.ProviderClient) (map[string]types.TenantSnapshots, error)
+	GetPools(provider *gophercloud.ProviderClient) (map[string]types.Pool, error)
+	GetServices(provider *gophercloud.ProviderClient) (map[string]map[string]types.CinderService, error)
+	GetBackups(provider *gophercloud.ProviderClient) (map[string]types.TenantBackups, error)
 }
 
 // Services serves as a API calls dispatcher
@@ -43,42 +52,115 @@ func (c *Service) Set(new Cinderer) {
 }
 
 // GetLimits dispatches call to proper API version calls to collect limits metrics
-func (s Service) GetLimits(provider *gophercloud.ProviderClient) (types.Limits, error) {
+func (s Service) GetLimits(provider *gophercloud.ProviderClient) (types.TenantLimits, error) {
 	return s.cinder.GetLimits(provider)
 }
 
+// GetQuotas dispatches call to proper API version calls to collect limits metrics of given tenant using admin API
+func (s Service) GetQuotas(provider *gophercloud.ProviderClient, tenantID string) (types.TenantLimits, error) {
+	return s.cinder.GetQuotas(provider, tenantID)
+}
+
 // GetVolumes dispatches call to proper API version calls to collect volumes metrics
-func (s Service) GetVolumes(provider *gophercloud.ProviderClient) (map[string]types.Volumes, error) {
+func (s Service) GetVolumes(provider *gophercloud.ProviderClient) (map[string]types.TenantVolumes, error) {
 	return s.cinder.GetVolumes(provider)
 }
 
 // GetSnapshots dispatches call to proper API version calls to collect snapshot metrics
-func (s Service) GetSnapshots(provider *gophercloud.ProviderClient) (map[string]types.Snapshots, error) {
+func (s Service) GetSnapshots(provider *gophercloud.ProviderClient) (map[string]types.TenantSnapshots, error) {
 	return s.cinder.GetSnapshots(provider)
 }
 
+// GetPools dispatches call to proper API version calls to collect backend pools metrics
+func (s Service) GetPools(provider *gophercloud.ProviderClient) (map[string]types.Pool, error) {
+	return s.cinder.GetPools(provider)
+}
+
+// GetServices dispatches call to proper API version calls to collect cinder services health metrics
+func (s Service) GetServices(provider *gophercloud.ProviderClient) (map[string]map[string]types.CinderService, error) {
+	return s.cinder.GetServices(provider)
+}
+
+// GetBackups dispatches call to proper API version calls to collect backups metrics
+func (s Service) GetBackups(provider *gophercloud.ProviderClient) (map[string]types.TenantBackups, error) {
+	return s.cinder.GetBackups(provider)
+}
+
+// Options holds settings of selected Cinder API version dispatcher
+type Options struct {
+	// PageSize limits number of volumes, snapshots and backups listed in single request
+	PageSize int
+	// Endpoint selects region and interface of Cinder endpoints used for dispatch and collection
+	Endpoint gophercloud.EndpointOpts
+}
+
+// endpointTypes maps Cinder API versions to types of their endpoints in service catalog
+var endpointTypes = map[string]string{
+	"v1.0": "volume",
+	"v2.0": "volumev2",
+	"v3.0": "volumev3",
+}
+
+// hasEndpoint checks whether endpoint of given Cinder API version is found in service catalog for given region and interface
+// Versions unknown to dispatcher are not checked, they are rejected when dispatching
+func hasEndpoint(provider *gophercloud.ProviderClient, eo gophercloud.EndpointOpts, version string) bool {
+	endpointType, known := endpointTypes[version]
+	if !known {
+		return true
+	}
+	eo.Type = endpointType
+	eo.ApplyDefaults(endpointType)
+	_, err := provider.EndpointLocator(eo)
+	return err == nil
+}
+
 // Dispatch redirects to selected Cinder API version based on priority
-func Dispatch(provider *gophercloud.ProviderClient) Service {
+// Only versions with endpoint in service catalog are considered, as versions are listed by any Cinder endpoint
+// It returns error when API versions cannot be retrieved or none of them is supported
+func Dispatch(provider *gophercloud.ProviderClient, opts Options) (Service, error) {
+	service := Service{}
+
 	cmn := openstackintel.Common{}
-	versions, err := cmn.GetApiVersions(provider)
+	apiVersions, err := cmn.GetApiVersionsDetails(provider, opts.Endpoint)
 	if err != nil {
-		panic(err)
+		return service, err
+	}
+
+	versions := []string{}
+	details := map[string]apiversionsintel.APIVersion{}
+	for _, apiVersion := range apiVersions {
+		if !hasEndpoint(provider, opts.Endpoint, apiVersion.ID) {
+			continue
+		}
+		versions = append(versions, apiVersion.ID)
+		details[apiVersion.ID] = apiVersion
+	}
+	if len(versions) == 0 && len(apiVersions) > 0 {
+		return service, fmt.Errorf("No endpoint of available Cinder API versions found in service catalog")
 	}
 
 	chosen, err := openstackintel.ChooseVersion(versions)
 	if err != nil {
-		panic(err)
+		return service, err
 	}
 
-	service := Service{}
 	switch chosen {
 	case "v1.0":
-		service.Set(cinderv1.ServiceV1{})
+		service.Set(cinderv1.ServiceV1{PageSize: opts.PageSize, Endpoint: opts.Endpoint})
 	case "v2.0":
-		service.Set(cinderv2.ServiceV2{})
+		service.Set(cinderv2.ServiceV2{PageSize: opts.PageSize, Endpoint: opts.Endpoint})
+	case "v3.0":
+		microversion, err := openstackv3.NegotiateMicroversion(details[chosen].MinVersion, details[chosen].Version)
+		if err != nil {
+			return service, err
+		}
+		cinder := cinderv3.NewServiceV3(microversion)
+		cinder.PageSize = opts.PageSize
+		cinder.Endpoint = opts.Endpoint
+		service.Set(cinder)
 	default:
-		panic("Could not select dispatcher")
+		return service, fmt.Errorf("Could not select dispatcher for Cinder API version %s", chosen)
 	}
 
-	return service
+	return service, nil
 }
@@ -0,0 +1,174 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/rackspace/gophercloud"
+	th "github.com/rackspace/gophercloud/testhelper"
+	. "github.com/smartystreets/goconvey/convey"
+
+	cinderv2 "github.com/intelsdi-x/snap-plugin-collector-cinder/openstack/v2/cinder"
+	openstackv3 "github.com/intelsdi-x/snap-plugin-collector-cinder/openstack/v3"
+	cinderv3 "github.com/intelsdi-x/snap-plugin-collector-cinder/openstack/v3/cinder"
+)
+
+func TestDispatch(t *testing.T) {
+	Convey("Given provider client for Cinder endpoint", t, func() {
+		th.SetupHTTP()
+		defer th.TeardownHTTP()
+
+		provider := &gophercloud.ProviderClient{
+			TokenID: "2ed210f132564f21b178afb197ee99e3",
+			EndpointLocator: func(eo gophercloud.EndpointOpts) (string, error) {
+				return th.Endpoint() + "v2/v2ffff/", nil
+			},
+		}
+
+		Convey("When Cinder API versions cannot be retrieved", func() {
+			th.Mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			})
+			_, err := Dispatch(provider, Options{})
+
+			Convey("Then error is returned instead of panic", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When only unknown Cinder API version is available", func() {
+			registerVersions("v9.0")
+			_, err := Dispatch(provider, Options{})
+
+			Convey("Then error is returned instead of panic", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When Cinder API version 2 is available", func() {
+			registerVersions("v2.0")
+			service, err := Dispatch(provider, Options{})
+
+			Convey("Then service is dispatched to API version 2", func() {
+				So(err, ShouldBeNil)
+				So(service.cinder, ShouldHaveSameTypeAs, cinderv2.ServiceV2{})
+			})
+		})
+
+		Convey("When Cinder API version 3 is available", func() {
+			registerVersionsWithMicroversions("v3.0", "3.0", "3.59")
+			service, err := Dispatch(provider, Options{})
+
+			Convey("Then service is dispatched to API version 3 with negotiated microversion", func() {
+				So(err, ShouldBeNil)
+				So(service.cinder, ShouldHaveSameTypeAs, cinderv3.ServiceV3{})
+				So(service.cinder.(cinderv3.ServiceV3).Microversion, ShouldEqual, openstackv3.MaxMicroversion)
+			})
+		})
+
+		Convey("When Cinder API version 3 is available without endpoint in service catalog", func() {
+			provider.EndpointLocator = func(eo gophercloud.EndpointOpts) (string, error) {
+				if eo.Type == "volumev3" {
+					return "", fmt.Errorf("No suitable endpoint could be found in the service catalog.")
+				}
+				return th.Endpoint() + "v2/v2ffff/", nil
+			}
+
+			Convey("and API version 2 is available", func() {
+				registerVersions("v2.0", "v3.0")
+				service, err := Dispatch(provider, Options{})
+
+				Convey("Then service falls back to API version 2", func() {
+					So(err, ShouldBeNil)
+					So(service.cinder, ShouldHaveSameTypeAs, cinderv2.ServiceV2{})
+				})
+			})
+
+			Convey("and no other API version is available", func() {
+				registerVersions("v3.0")
+				_, err := Dispatch(provider, Options{})
+
+				Convey("Then error is returned", func() {
+					So(err, ShouldNotBeNil)
+				})
+			})
+		})
+
+		Convey("When page size is configured", func() {
+			registerVersionsWithMicroversions("v3.0", "3.0", "3.59")
+			service, err := Dispatch(provider, Options{PageSize: 100})
+
+			Convey("Then page size is passed to dispatched API version", func() {
+				So(err, ShouldBeNil)
+				So(service.cinder.(cinderv3.ServiceV3).PageSize, ShouldEqual, 100)
+			})
+		})
+
+		Convey("When Cinder API version 3 requires newer microversion", func() {
+			registerVersionsWithMicroversions("v3.0", "3.50", "3.59")
+			_, err := Dispatch(provider, Options{})
+
+			Convey("Then error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+func registerVersions(versions ...string) {
+	th.Mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		entries := []string{}
+		for _, version := range versions {
+			entries = append(entries, versionEntry(version, "", ""))
+		}
+		fmt.Fprintf(w, `{"versions": [%s]}`, strings.Join(entries, ","))
+	})
+}
+
+func registerVersionsWithMicroversions(version, minMicroversion, maxMicroversion string) {
+	th.Mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		fmt.Fprintf(w, `{"versions": [%s]}`, versionEntry(version, minMicroversion, maxMicroversion))
+	})
+}
+
+func versionEntry(version, minMicroversion, maxMicroversion string) string {
+	return fmt.Sprintf(`
+		{
+			"id": "%s",
+			"links": [
+				{
+					"href": "%s",
+					"rel": "self"
+				}
+			],
+			"status": "CURRENT",
+			"updated": "2012-11-21T11:33:21Z",
+			"min_version": "%s",
+			"version": "%s"
+		}
+	`, version, th.Endpoint(), minMicroversion, maxMicroversion)
+}
@@ -0,0 +1,86 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// tls contains HTTP transport settings for Keystone and Cinder endpoints
+
+package openstack
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/rackspace/gophercloud"
+	"github.com/rackspace/gophercloud/openstack"
+)
+
+// newClient creates unauthenticated provider client for Keystone endpoint using TLS settings of given options
+func newClient(opts AuthOptions) (*gophercloud.ProviderClient, error) {
+	provider, err := openstack.NewClient(opts.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := newTransport(opts)
+	if err != nil {
+		return nil, err
+	}
+	if transport != nil {
+		provider.HTTPClient.Transport = transport
+	}
+
+	return provider, nil
+}
+
+// newTransport creates HTTP transport which verifies server certificates with CA certificates from CAFile
+// and presents client certificate from CertFile and KeyFile, verification is skipped when Insecure is set
+// It returns nil when no TLS settings are given, so that default transport is used
+func newTransport(opts AuthOptions) (http.RoundTripper, error) {
+	if opts.CAFile == "" && opts.CertFile == "" && opts.KeyFile == "" && !opts.Insecure {
+		return nil, nil
+	}
+
+	config := &tls.Config{InsecureSkipVerify: opts.Insecure}
+
+	if opts.CAFile != "" {
+		caCerts, err := ioutil.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCerts) {
+			return nil, fmt.Errorf("No PEM encoded certificates found in CA file %s", opts.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if opts.CertFile != "" || opts.KeyFile != "" {
+		if opts.CertFile == "" || opts.KeyFile == "" {
+			return nil, errors.New("Both certificate and key files are required for client certificate")
+		}
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: config,
+	}, nil
+}
@@ -0,0 +1,201 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package openstack
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const tlsToken = "2ed210f132564f21b178afb197ee99e3"
+
+func TestAuthenticateTLS(t *testing.T) {
+	Convey("Given Keystone endpoint served over TLS", t, func() {
+		dir, err := ioutil.TempDir("", "tls")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		server := httptest.NewUnstartedServer(keystoneTLSHandler())
+		server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+		server.StartTLS()
+		defer server.Close()
+
+		caFile := filepath.Join(dir, "ca.pem")
+		So(writePEM(caFile, "CERTIFICATE", server.TLS.Certificates[0].Certificate[0]), ShouldBeNil)
+
+		opts := AuthOptions{Endpoint: server.URL + "/v3/", Token: tlsToken}
+
+		Convey("When server certificate is not trusted", func() {
+			_, err := Authenticate(opts, "")
+
+			Convey("Then error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When CA file with server certificate is given", func() {
+			opts.CAFile = caFile
+			provider, err := Authenticate(opts, "")
+
+			Convey("Then provider is authenticated", func() {
+				So(err, ShouldBeNil)
+				So(provider.TokenID, ShouldEqual, tlsToken)
+			})
+		})
+
+		Convey("When certificate verification is disabled", func() {
+			opts.Insecure = true
+			provider, err := Authenticate(opts, "")
+
+			Convey("Then provider is authenticated", func() {
+				So(err, ShouldBeNil)
+				So(provider.TokenID, ShouldEqual, tlsToken)
+			})
+		})
+
+		Convey("When password is used with CA file", func() {
+			provider, err := Authenticate(AuthOptions{Endpoint: server.URL + "/v3/", User: "me", Password: "secret", CAFile: caFile}, "")
+
+			Convey("Then provider is authenticated", func() {
+				So(err, ShouldBeNil)
+				So(provider.TokenID, ShouldEqual, tlsToken)
+			})
+		})
+
+		Convey("When client certificate is given", func() {
+			opts.Endpoint = server.URL + "/mtls/v3/"
+			opts.CAFile = caFile
+			opts.CertFile = filepath.Join(dir, "client.pem")
+			opts.KeyFile = filepath.Join(dir, "client.key")
+			So(writeClientCertificate(opts.CertFile, opts.KeyFile), ShouldBeNil)
+			_, err := Authenticate(opts, "")
+
+			Convey("Then client certificate is presented to server", func() {
+				So(err, ShouldBeNil)
+			})
+		})
+
+		Convey("When client certificate is required but not given", func() {
+			opts.Endpoint = server.URL + "/mtls/v3/"
+			opts.CAFile = caFile
+			_, err := Authenticate(opts, "")
+
+			Convey("Then error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When client certificate is given without key", func() {
+			opts.CAFile = caFile
+			opts.CertFile = filepath.Join(dir, "client.pem")
+			_, err := Authenticate(opts, "")
+
+			Convey("Then error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When CA file does not contain certificates", func() {
+			opts.CAFile = filepath.Join(dir, "empty.pem")
+			So(ioutil.WriteFile(opts.CAFile, []byte("not a certificate"), 0600), ShouldBeNil)
+			_, err := Authenticate(opts, "")
+
+			Convey("Then error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+// keystoneTLSHandler serves Keystone v3 token requests, client certificate is required for endpoint prefixed with /mtls
+func keystoneTLSHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+		if strings.HasPrefix(path, "/mtls/") {
+			if len(r.TLS.PeerCertificates) == 0 || r.TLS.PeerCertificates[0].Subject.CommonName != "client" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			path = strings.TrimPrefix(path, "/mtls")
+		}
+		if path != "/v3/auth/tokens" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Add("X-Subject-Token", tlsToken)
+		w.Header().Add("Content-Type", "application/json")
+		if r.Method == "POST" {
+			w.WriteHeader(http.StatusCreated)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+		fmt.Fprintf(w, tokenBody, "https://"+r.Host+"/v2/", "null")
+	})
+}
+
+// writeClientCertificate generates self-signed client certificate and its key
+func writeClientCertificate(certFile, keyFile string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	if err := writePEM(certFile, "CERTIFICATE", cert); err != nil {
+		return err
+	}
+	return writePEM(keyFile, "EC PRIVATE KEY", keyDER)
+}
+
+// writePEM writes PEM encoded block to file
+func writePEM(file, blockType string, data []byte) error {
+	return ioutil.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: data}), 0600)
+}
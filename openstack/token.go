@@ -0,0 +1,140 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// token contains authentication with pre-issued Keystone token
+
+package openstack
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rackspace/gophercloud"
+
+	tokensintel "github.com/intelsdi-x/snap-plugin-collector-cinder/openstack/tokens"
+)
+
+// authenticateToken creates provider client using pre-issued token validated with Keystone v3 API
+// Token is always scoped by its issuer, so error is returned when other tenant is requested
+// Token file is read again when it changes and when token is rejected
+func authenticateToken(opts AuthOptions, tenant string) (*gophercloud.ProviderClient, error) {
+	client, err := newIdentityV3(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := newClient(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	source := &tokenSource{token: opts.Token, file: opts.TokenFile}
+	provider.HTTPClient.Transport = tokenTransport{base: provider.HTTPClient.Transport, source: source}
+
+	auth := func(reload bool) error {
+		token, err := source.get(reload)
+		if err != nil {
+			return err
+		}
+		return useToken(provider, tokensintel.Get(client, token), tenant)
+	}
+
+	if err := auth(false); err != nil {
+		return nil, err
+	}
+	provider.ReauthFunc = func() error {
+		return auth(true)
+	}
+
+	return provider, nil
+}
+
+// tokenSource provides pre-issued token given directly or read from file
+type tokenSource struct {
+	sync.Mutex
+	token   string
+	file    string
+	modTime time.Time
+}
+
+// get returns current token, token file is read again if it was modified since last read or reload is requested
+func (s *tokenSource) get(reload bool) (string, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.file == "" {
+		return s.token, nil
+	}
+
+	info, err := os.Stat(s.file)
+	if err != nil {
+		return "", err
+	}
+	if !reload && s.token != "" && info.ModTime().Equal(s.modTime) {
+		return s.token, nil
+	}
+
+	data, err := ioutil.ReadFile(s.file)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("Token file %s is empty", s.file)
+	}
+
+	s.token = token
+	s.modTime = info.ModTime()
+	return s.token, nil
+}
+
+// tokenTransport sends current token of token source with every authenticated request
+// Token changed in token file is used without waiting for current token to be rejected
+type tokenTransport struct {
+	base   http.RoundTripper
+	source *tokenSource
+}
+
+// RoundTrip sends request with current token using base transport
+func (t tokenTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	if r.Header.Get("X-Auth-Token") == "" {
+		return base.RoundTrip(r)
+	}
+
+	token, err := t.source.get(false)
+	if err != nil {
+		return nil, err
+	}
+
+	// request must not be modified by transport
+	req := new(http.Request)
+	*req = *r
+	req.Header = http.Header{}
+	for key, values := range r.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("X-Auth-Token", token)
+
+	return base.RoundTrip(req)
+}
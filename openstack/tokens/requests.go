@@ -0,0 +1,172 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// requests contains Keystone v3 API requests for tokens
+
+package tokens
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rackspace/gophercloud"
+)
+
+// CreateOptsBuilder allows to build body of token request for different authentication methods
+type CreateOptsBuilder interface {
+	ToTokenCreateMap() (map[string]interface{}, error)
+}
+
+// ApplicationCredentialOpts holds application credential used to issue token. It is passed to the tokens.Create function.
+// Application credential is identified either by ID or by Name of credential owned by user given with UserName and user domain
+type ApplicationCredentialOpts struct {
+	ID         string
+	Name       string
+	Secret     string
+	UserName   string
+	DomainName string
+	DomainID   string
+}
+
+// ToTokenCreateMap builds application credential authentication request body
+func (opts ApplicationCredentialOpts) ToTokenCreateMap() (map[string]interface{}, error) {
+	if opts.Secret == "" {
+		return nil, errors.New("Application credential secret is required")
+	}
+
+	credential := map[string]interface{}{"secret": opts.Secret}
+	switch {
+	case opts.ID != "":
+		credential["id"] = opts.ID
+	case opts.Name != "" && opts.UserName != "":
+		user := map[string]interface{}{"name": opts.UserName}
+		if domain := domainMap(opts.DomainName, opts.DomainID); domain != nil {
+			user["domain"] = domain
+		}
+		credential["name"] = opts.Name
+		credential["user"] = user
+	default:
+		return nil, errors.New("Application credential ID or name along with user name is required")
+	}
+
+	return map[string]interface{}{
+		"auth": map[string]interface{}{
+			"identity": map[string]interface{}{
+				"methods":                []string{"application_credential"},
+				"application_credential": credential,
+			},
+		},
+	}, nil
+}
+
+// PasswordOpts holds user credentials used to issue token. It is passed to the tokens.Create function.
+// User and project are identified by names within their own domains, each domain is given either by name or by ID
+// Token is not scoped when ProjectName is empty
+type PasswordOpts struct {
+	UserName          string
+	Password          string
+	UserDomainName    string
+	UserDomainID      string
+	ProjectName       string
+	ProjectDomainName string
+	ProjectDomainID   string
+}
+
+// ToTokenCreateMap builds password authentication request body, scoped to project when ProjectName is set
+func (opts PasswordOpts) ToTokenCreateMap() (map[string]interface{}, error) {
+	if opts.UserName == "" || opts.Password == "" {
+		return nil, errors.New("User name and password are required")
+	}
+	if opts.UserDomainName != "" && opts.UserDomainID != "" {
+		return nil, errors.New("User domain name and ID are mutually exclusive")
+	}
+	if opts.ProjectDomainName != "" && opts.ProjectDomainID != "" {
+		return nil, errors.New("Project domain name and ID are mutually exclusive")
+	}
+
+	user := map[string]interface{}{"name": opts.UserName, "password": opts.Password}
+	if domain := domainMap(opts.UserDomainName, opts.UserDomainID); domain != nil {
+		user["domain"] = domain
+	}
+	auth := map[string]interface{}{
+		"identity": map[string]interface{}{
+			"methods":  []string{"password"},
+			"password": map[string]interface{}{"user": user},
+		},
+	}
+
+	if opts.ProjectName != "" {
+		domain := domainMap(opts.ProjectDomainName, opts.ProjectDomainID)
+		if domain == nil {
+			return nil, fmt.Errorf("Project domain is required to scope token to project %s", opts.ProjectName)
+		}
+		auth["scope"] = map[string]interface{}{
+			"project": map[string]interface{}{"name": opts.ProjectName, "domain": domain},
+		}
+	}
+
+	return map[string]interface{}{"auth": auth}, nil
+}
+
+// domainMap builds domain reference of request body, ID is preferred over name
+// It returns nil if neither name nor ID is given
+func domainMap(name, id string) map[string]interface{} {
+	if id != "" {
+		return map[string]interface{}{"id": id}
+	}
+	if name != "" {
+		return map[string]interface{}{"name": name}
+	}
+	return nil
+}
+
+// Create issues new token by sending POST call to keystonehost:5000/v3/auth/tokens
+func Create(client *gophercloud.ServiceClient, opts CreateOptsBuilder) CreateResult {
+	var res CreateResult
+
+	reqBody, err := opts.ToTokenCreateMap()
+	if err != nil {
+		res.Err = err
+		return res
+	}
+
+	resp, err := client.Post(createURL(client), reqBody, &res.Body, &gophercloud.RequestOpts{
+		OkCodes: []int{201},
+	})
+	if resp != nil {
+		res.Header = resp.Header
+	}
+	res.Err = err
+	return res
+}
+
+// Get validates token and retrieves its scope and service catalog by sending GET call to keystonehost:5000/v3/auth/tokens
+// Token is validated with itself, so no other credentials are needed
+func Get(client *gophercloud.ServiceClient, token string) GetResult {
+	var res GetResult
+
+	resp, err := client.Request("GET", createURL(client), gophercloud.RequestOpts{
+		JSONResponse: &res.Body,
+		MoreHeaders: map[string]string{
+			"X-Auth-Token":    token,
+			"X-Subject-Token": token,
+		},
+		OkCodes: []int{200},
+	})
+	if resp != nil {
+		res.Header = resp.Header
+	}
+	res.Err = err
+	return res
+}
@@ -0,0 +1,96 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// results contains Keystone v3 API responses and their processing for tokens
+
+package tokens
+
+import (
+	"errors"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/rackspace/gophercloud"
+	tokens3 "github.com/rackspace/gophercloud/openstack/identity/v3/tokens"
+)
+
+// Project contains information about project to which token is scoped
+type Project struct {
+	ID   string `mapstructure:"id"`
+	Name string `mapstructure:"name"`
+}
+
+// Token contains issued token ID along with its scope
+type Token struct {
+	ID      string
+	Project Project
+}
+
+type commonResult struct {
+	gophercloud.Result
+}
+
+// CreateResult contains the response body, headers and error from a Create request
+type CreateResult struct {
+	commonResult
+}
+
+// GetResult contains the response body, headers and error from a Get request
+type GetResult struct {
+	commonResult
+}
+
+// ExtractToken returns token issued by Keystone, token ID is taken from X-Subject-Token header
+func (r commonResult) ExtractToken() (*Token, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+
+	var res struct {
+		Token struct {
+			Project Project `mapstructure:"project"`
+		} `mapstructure:"token"`
+	}
+
+	err := mapstructure.Decode(r.Body, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	id := r.Header.Get("X-Subject-Token")
+	if id == "" {
+		return nil, errors.New("Token ID not found in response")
+	}
+
+	return &Token{ID: id, Project: res.Token.Project}, nil
+}
+
+// ExtractServiceCatalog returns service catalog included in issued token
+func (r commonResult) ExtractServiceCatalog() (*tokens3.ServiceCatalog, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+
+	var res struct {
+		Token struct {
+			Entries []tokens3.CatalogEntry `mapstructure:"catalog"`
+		} `mapstructure:"token"`
+	}
+
+	err := mapstructure.Decode(r.Body, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tokens3.ServiceCatalog{Entries: res.Token.Entries}, nil
+}
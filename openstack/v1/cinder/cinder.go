@@ -17,23 +17,35 @@ limitations under the License.
 package cinder
 
 import (
+	"time"
+
 	"github.com/rackspace/gophercloud"
 	"github.com/rackspace/gophercloud/openstack"
-	"github.com/rackspace/gophercloud/openstack/blockstorage/v1/snapshots"
-	"github.com/rackspace/gophercloud/openstack/blockstorage/v1/volumes"
+	"github.com/rackspace/gophercloud/pagination"
 
+	openstackintel "github.com/intelsdi-x/snap-plugin-collector-cinder/openstack"
+	backupsintel "github.com/intelsdi-x/snap-plugin-collector-cinder/openstack/backups"
 	limitsintel "github.com/intelsdi-x/snap-plugin-collector-cinder/openstack/limits"
+	osservicesintel "github.com/intelsdi-x/snap-plugin-collector-cinder/openstack/osservices"
+	quotasetsintel "github.com/intelsdi-x/snap-plugin-collector-cinder/openstack/quotasets"
+	snapshotsintel "github.com/intelsdi-x/snap-plugin-collector-cinder/openstack/v1/snapshots"
+	volumesintel "github.com/intelsdi-x/snap-plugin-collector-cinder/openstack/v1/volumes"
 	"github.com/intelsdi-x/snap-plugin-collector-cinder/types"
 )
 
 // ServiceV1 serves as dispatcher for Cinder API version 1.0
-type ServiceV1 struct{}
+// PageSize limits number of volumes, snapshots and backups listed in single request, Cinder osapi_max_limit is used when not set
+// Endpoint selects region and interface of Cinder endpoint, first public endpoint is used when not set
+type ServiceV1 struct {
+	PageSize int
+	Endpoint gophercloud.EndpointOpts
+}
 
 // GetLimits collects tenant limits by sending REST call to cinderhost:8776/v1/tenant_id/limits
-func (s ServiceV1) GetLimits(provider *gophercloud.ProviderClient) (types.Limits, error) {
-	limits := types.Limits{}
+func (s ServiceV1) GetLimits(provider *gophercloud.ProviderClient) (types.TenantLimits, error) {
+	limits := types.TenantLimits{}
 
-	client, err := openstack.NewBlockStorageV1(provider, gophercloud.EndpointOpts{})
+	client, err := openstack.NewBlockStorageV1(provider, s.Endpoint)
 	if err != nil {
 		return limits, err
 	}
@@ -45,71 +57,194 @@ func (s ServiceV1) GetLimits(provider *gophercloud.ProviderClient) (types.Limits
 
 	limits.MaxTotalVolumes = tenantLimits.MaxTotalVolumes
 	limits.MaxTotalVolumeGigabytes = tenantLimits.MaxTotalVolumeGigabytes
+	limits.MaxTotalSnapshots = tenantLimits.MaxTotalSnapshots
+	limits.MaxTotalBackups = tenantLimits.MaxTotalBackups
+	limits.MaxTotalBackupGigabytes = tenantLimits.MaxTotalBackupGigabytes
+	limits.TotalVolumesUsed = tenantLimits.TotalVolumesUsed
+	limits.TotalGigabytesUsed = tenantLimits.TotalGigabytesUsed
+	limits.TotalSnapshotsUsed = tenantLimits.TotalSnapshotsUsed
+	limits.TotalBackupsUsed = tenantLimits.TotalBackupsUsed
+	limits.TotalBackupGigabytesUsed = tenantLimits.TotalBackupGigabytesUsed
 
 	return limits, nil
 }
 
-// GetVolumes collects volumes data by sending REST call to cinderhost:8776/v1/tenant_id/volumes
-func (s ServiceV1) GetVolumes(provider *gophercloud.ProviderClient) (map[string]types.Volumes, error) {
-	vols := map[string]types.Volumes{}
+// GetQuotas collects limits of given tenant by sending REST call to cinderhost:8776/v1/tenant_id/os-quota-sets/tenant_id?usage=true
+// It requires administrative privileges when provider is not authenticated for given tenant
+func (s ServiceV1) GetQuotas(provider *gophercloud.ProviderClient, tenantID string) (types.TenantLimits, error) {
+	limits := types.TenantLimits{}
 
-	client, err := openstack.NewBlockStorageV1(provider, gophercloud.EndpointOpts{})
+	client, err := openstack.NewBlockStorageV1(provider, s.Endpoint)
 	if err != nil {
-		return vols, err
+		return limits, err
 	}
 
-	//opts := volumes.ListOpts{AllTenants: true}
-	opts := volumes.ListOpts{}
-
-	pager := volumes.List(client, opts)
-	page, err := pager.AllPages()
+	quotaSet, err := quotasetsintel.Get(client, tenantID).Extract()
 	if err != nil {
-		return vols, err
+		return limits, err
 	}
 
-	volumeList, err := volumes.ExtractVolumes(page)
+	limits.MaxTotalVolumes = quotaSet["volumes"].Limit
+	limits.MaxTotalVolumeGigabytes = quotaSet["gigabytes"].Limit
+	limits.MaxTotalSnapshots = quotaSet["snapshots"].Limit
+	limits.MaxTotalBackups = quotaSet["backups"].Limit
+	limits.MaxTotalBackupGigabytes = quotaSet["backup_gigabytes"].Limit
+	limits.TotalVolumesUsed = quotaSet["volumes"].InUse
+	limits.TotalGigabytesUsed = quotaSet["gigabytes"].InUse
+	limits.TotalSnapshotsUsed = quotaSet["snapshots"].InUse
+	limits.TotalBackupsUsed = quotaSet["backups"].InUse
+	limits.TotalBackupGigabytesUsed = quotaSet["backup_gigabytes"].InUse
+	limits.TotalVolumesReserved = quotaSet["volumes"].Reserved
+	limits.TotalGigabytesReserved = quotaSet["gigabytes"].Reserved
+	limits.TotalSnapshotsReserved = quotaSet["snapshots"].Reserved
+	limits.TotalBackupsReserved = quotaSet["backups"].Reserved
+	limits.TotalBackupGigabytesReserved = quotaSet["backup_gigabytes"].Reserved
+	limits.Reserved = true
+
+	limits.Types = map[string]types.VolumeTypeLimits{}
+	for volumeType, quota := range quotaSet.VolumeTypes() {
+		limits.Types[volumeType] = types.VolumeTypeLimits{
+			MaxVolumes:    quota.Volumes.Limit,
+			MaxGigabytes:  quota.Gigabytes.Limit,
+			UsedVolumes:   quota.Volumes.InUse,
+			UsedGigabytes: quota.Gigabytes.InUse,
+		}
+	}
+
+	return limits, nil
+}
+
+// GetVolumes collects volumes data by sending REST call to cinderhost:8776/v1/tenant_id/volumes/detail?all_tenants=true
+// Following pages are requested using markers from volumes_links, volumes are aggregated page by page
+func (s ServiceV1) GetVolumes(provider *gophercloud.ProviderClient) (map[string]types.TenantVolumes, error) {
+	vols := map[string]types.TenantVolumes{}
+
+	client, err := openstack.NewBlockStorageV1(provider, s.Endpoint)
 	if err != nil {
 		return vols, err
 	}
 
-	for _, volume := range volumeList {
-		volCounts := vols["volume.OsVolTenantAttrTenantID"]
-		volCounts.Count += 1
-		volCounts.Bytes += volume.Size * 1024 * 1024 * 1024
-		vols["volume.OsVolTenantAttrTenantID"] = volCounts
-
+	opts := volumesintel.ListOpts{AllTenants: true, Limit: s.PageSize}
+
+	pager := volumesintel.List(client, opts)
+	err = pager.EachPage(func(page pagination.Page) (bool, error) {
+		volumeList, err := volumesintel.ExtractVolumes(page)
+		if err != nil {
+			return false, err
+		}
+
+		for _, volume := range volumeList {
+			volCounts := vols[volume.OsVolTenantAttrTenantID]
+			volCounts.Add(volume.Size, volume.Status, volume.VolumeType)
+			vols[volume.OsVolTenantAttrTenantID] = volCounts
+		}
+		return true, nil
+	})
+	if err != nil {
+		return vols, err
 	}
 
 	return vols, nil
 }
 
-// GetSnapshots collects snapshot data by sending REST call to cinderhost:8776/v1/tenant_id/snapshots
-func (s ServiceV1) GetSnapshots(provider *gophercloud.ProviderClient) (map[string]types.Snapshots, error) {
-	snaps := map[string]types.Snapshots{}
+// GetSnapshots collects snapshot data by sending REST call to cinderhost:8776/v1/tenant_id/snapshots/detail?all_tenants=true
+// Following pages are requested using markers from snapshots_links, snapshots are aggregated page by page
+func (s ServiceV1) GetSnapshots(provider *gophercloud.ProviderClient) (map[string]types.TenantSnapshots, error) {
+	snaps := map[string]types.TenantSnapshots{}
 
-	client, err := openstack.NewBlockStorageV1(provider, gophercloud.EndpointOpts{})
+	client, err := openstack.NewBlockStorageV1(provider, s.Endpoint)
 	if err != nil {
 		return snaps, err
 	}
 
-	opts := snapshots.ListOpts{}
-
-	pager := snapshots.List(client, opts)
-	page, err := pager.AllPages()
+	opts := snapshotsintel.ListOpts{AllTenants: true, Limit: s.PageSize}
+
+	pager := snapshotsintel.List(client, opts)
+	err = pager.EachPage(func(page pagination.Page) (bool, error) {
+		snapshotList, err := snapshotsintel.ExtractSnapshots(page)
+		if err != nil {
+			return false, err
+		}
+
+		for _, snapshot := range snapshotList {
+			snapCounts := snaps[snapshot.OsExtendedSnapshotAttributesProjectID]
+			snapCounts.Add(snapshot.Size, snapshot.Status)
+			snaps[snapshot.OsExtendedSnapshotAttributesProjectID] = snapCounts
+		}
+		return true, nil
+	})
 	if err != nil {
 		return snaps, err
 	}
 
-	snapshotList, err := snapshots.ExtractSnapshots(page)
+	return snaps, nil
+}
+
+// GetPools is not supported, scheduler statistics are not available in Cinder API version 1
+func (s ServiceV1) GetPools(provider *gophercloud.ProviderClient) (map[string]types.Pool, error) {
+	return nil, openstackintel.ErrNotSupported
+}
+
+// GetServices collects cinder services health by sending REST call to cinderhost:8776/v1/tenant_id/os-services
+// Returned services are grouped by binary and host
+func (s ServiceV1) GetServices(provider *gophercloud.ProviderClient) (map[string]map[string]types.CinderService, error) {
+	services := map[string]map[string]types.CinderService{}
+
+	client, err := openstack.NewBlockStorageV1(provider, s.Endpoint)
+	if err != nil {
+		return services, err
+	}
+
+	serviceList, err := osservicesintel.List(client).Extract()
 	if err != nil {
-		return snaps, err
+		return services, err
 	}
 
-	for _, snapshot := range snapshotList {
-		snapCounts := snaps["tenant_id"]
-		snapCounts.Count += 1
-		snapCounts.Bytes += snapshot.Size * 1024 * 1024 * 1024
+	now := time.Now().UTC()
+	for _, service := range serviceList {
+		if _, found := services[service.Binary]; !found {
+			services[service.Binary] = map[string]types.CinderService{}
+		}
+		services[service.Binary][service.Host] = types.NewCinderService(service.State, service.Status, service.SecondsSinceUpdate(now))
 	}
 
-	return snaps, nil
+	return services, nil
+}
+
+// GetBackups collects backups data by sending REST call to cinderhost:8776/v1/tenant_id/backups/detail?all_tenants=true
+// Following pages are requested using markers from backups_links
+// Backups are assigned to tenants owning backed up volumes when Cinder does not report backup project
+func (s ServiceV1) GetBackups(provider *gophercloud.ProviderClient) (map[string]types.TenantBackups, error) {
+	client, err := openstack.NewBlockStorageV1(provider, s.Endpoint)
+	if err != nil {
+		return map[string]types.TenantBackups{}, err
+	}
+
+	return backupsintel.TenantBackups(client, s.PageSize, func() (map[string]string, error) {
+		return volumeOwners(client, s.PageSize)
+	})
+}
+
+// volumeOwners returns tenant ID for each volume ID available in all tenants
+func volumeOwners(client *gophercloud.ServiceClient, pageSize int) (map[string]string, error) {
+	owners := map[string]string{}
+
+	opts := volumesintel.ListOpts{AllTenants: true, Limit: pageSize}
+	pager := volumesintel.List(client, opts)
+	err := pager.EachPage(func(page pagination.Page) (bool, error) {
+		volumes, err := volumesintel.ExtractVolumes(page)
+		if err != nil {
+			return false, err
+		}
+
+		for _, volume := range volumes {
+			owners[volume.ID] = volume.OsVolTenantAttrTenantID
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return owners, nil
 }
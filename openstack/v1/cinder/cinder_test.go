@@ -0,0 +1,243 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cinder
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/rackspace/gophercloud"
+	th "github.com/rackspace/gophercloud/testhelper"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const (
+	token     = "2ed210f132564f21b178afb197ee99e3"
+	tenant1ID = "admin_id123"
+	tenant2ID = "demo_id123"
+)
+
+func TestGetVolumes(t *testing.T) {
+	Convey("Given Cinder V1 volumes are requested", t, func() {
+		th.SetupHTTP()
+		defer th.TeardownHTTP()
+		registerVolumes(t)
+
+		Convey("When GetVolumes called", func() {
+			dispatch := ServiceV1{PageSize: 1}
+			volumes, err := dispatch.GetVolumes(newProvider())
+
+			Convey("Then volumes of all tenants are returned", func() {
+				So(err, ShouldBeNil)
+				So(len(volumes), ShouldEqual, 2)
+				So(volumes[tenant1ID].Count, ShouldEqual, 1)
+				So(volumes[tenant1ID].Bytes, ShouldEqual, 11*1024*1024*1024)
+				So(volumes[tenant1ID].Status["available"].Count, ShouldEqual, 1)
+				So(volumes[tenant2ID].Count, ShouldEqual, 1)
+				So(volumes[tenant2ID].Types["SSD"].Bytes, ShouldEqual, 22*1024*1024*1024)
+			})
+		})
+	})
+}
+
+func TestGetSnapshots(t *testing.T) {
+	Convey("Given Cinder V1 snapshots are requested", t, func() {
+		th.SetupHTTP()
+		defer th.TeardownHTTP()
+		registerSnapshots(t)
+
+		Convey("When GetSnapshots called", func() {
+			dispatch := ServiceV1{}
+			snapshots, err := dispatch.GetSnapshots(newProvider())
+
+			Convey("Then snapshots of all tenants are returned", func() {
+				So(err, ShouldBeNil)
+				So(len(snapshots), ShouldEqual, 2)
+				So(snapshots[tenant1ID].Count, ShouldEqual, 1)
+				So(snapshots[tenant1ID].Bytes, ShouldEqual, 5*1024*1024*1024)
+				So(snapshots[tenant2ID].Status["error"].Count, ShouldEqual, 1)
+			})
+		})
+	})
+}
+
+func TestGetBackups(t *testing.T) {
+	Convey("Given Cinder V1 backups are requested", t, func() {
+		th.SetupHTTP()
+		defer th.TeardownHTTP()
+		registerVolumes(t)
+		registerBackups(t)
+
+		Convey("When GetBackups called", func() {
+			dispatch := ServiceV1{PageSize: 1}
+			backups, err := dispatch.GetBackups(newProvider())
+
+			Convey("Then backups without project are assigned to tenants owning backed up volumes", func() {
+				So(err, ShouldBeNil)
+				So(len(backups), ShouldEqual, 2)
+				So(backups[tenant1ID].Count, ShouldEqual, 1)
+				So(backups[tenant2ID].Count, ShouldEqual, 1)
+				So(backups[tenant2ID].Bytes, ShouldEqual, 4*1024*1024*1024)
+				So(backups[tenant2ID].Status["available"].Count, ShouldEqual, 1)
+			})
+		})
+	})
+}
+
+func newProvider() *gophercloud.ProviderClient {
+	return &gophercloud.ProviderClient{
+		TokenID: token,
+		EndpointLocator: func(eo gophercloud.EndpointOpts) (string, error) {
+			return th.Endpoint() + "v1/v1ffff/", nil
+		},
+	}
+}
+
+func registerVolumes(t *testing.T) {
+	url := "/v1/v1ffff/volumes/detail"
+	th.Mux.HandleFunc(url, func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", token)
+		th.TestFormValues(t, r, map[string]string{"all_tenants": "true", "limit": "1"})
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		// volumes are listed one per page, following page is linked with marker of last volume
+		if r.FormValue("marker") != "" {
+			fmt.Fprintf(w, `
+			{
+				"volumes": [
+					{
+						"display_name": "test-volume",
+						"id": "vol2id_321",
+						"os-vol-tenant-attr:tenant_id": "%s",
+						"size": 22,
+						"status": "in-use",
+						"volume_type": "SSD"
+					}
+				]
+			}
+		`, tenant2ID)
+			return
+		}
+
+		fmt.Fprintf(w, `
+			{
+				"volumes": [
+					{
+						"display_name": "test_tenant_volume",
+						"id": "vol1id_123",
+						"os-vol-tenant-attr:tenant_id": "%s",
+						"size": 11,
+						"status": "available",
+						"volume_type": null
+					}
+				],
+				"volumes_links": [
+					{
+						"href": "%s",
+						"rel": "next"
+					}
+				]
+			}
+		`, tenant1ID, th.Endpoint()+url[1:]+"?all_tenants=true&limit=1&marker=vol1id_123")
+	})
+}
+
+func registerSnapshots(t *testing.T) {
+	th.Mux.HandleFunc("/v1/v1ffff/snapshots/detail", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", token)
+		th.TestFormValues(t, r, map[string]string{"all_tenants": "true"})
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		fmt.Fprintf(w, `
+			{
+				"snapshots": [
+					{
+						"created_at": "2016-02-21T19:59:15.000000",
+						"display_name": "snapshot_1",
+						"id": "snap1cccc",
+						"os-extended-snapshot-attributes:project_id": "%s",
+						"size": 5,
+						"status": "available",
+						"volume_id": "vol1id_123"
+					},
+					{
+						"created_at": "2016-02-22T19:59:15.000000",
+						"display_name": "snapshot_2",
+						"id": "snap2cccc",
+						"os-extended-snapshot-attributes:project_id": "%s",
+						"size": 2,
+						"status": "error",
+						"volume_id": "vol2id_321"
+					}
+				]
+			}
+		`, tenant1ID, tenant2ID)
+	})
+}
+
+func registerBackups(t *testing.T) {
+	url := "/v1/v1ffff/backups/detail"
+	th.Mux.HandleFunc(url, func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(t, r, "GET")
+		th.TestHeader(t, r, "X-Auth-Token", token)
+		th.TestFormValues(t, r, map[string]string{"all_tenants": "true", "limit": "1"})
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		// backups are listed one per page, following page is linked with marker of last backup
+		if r.FormValue("marker") != "" {
+			fmt.Fprintf(w, `
+			{
+				"backups": [
+					{
+						"created_at": "2016-02-21T10:00:00.000000",
+						"id": "backup2",
+						"size": 4,
+						"status": "available",
+						"volume_id": "vol2id_321"
+					}
+				]
+			}
+		`)
+			return
+		}
+
+		fmt.Fprintf(w, `
+			{
+				"backups": [
+					{
+						"created_at": "2016-02-20T10:00:00.000000",
+						"id": "backup1",
+						"os-backup-project-attr:project_id": "%s",
+						"size": 3,
+						"status": "error",
+						"volume_id": "vol1id_123"
+					}
+				],
+				"backups_links": [
+					{
+						"href": "%s",
+						"rel": "next"
+					}
+				]
+			}
+		`, tenant1ID, th.Endpoint()+url[1:]+"?all_tenants=true&limit=1&marker=backup1")
+	})
+}
@@ -17,7 +17,10 @@ package cinder
 import (
 	"fmt"
 	"net/http"
+	"strconv"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	th "github.com/rackspace/gophercloud/testhelper"
 	. "github.com/smartystreets/goconvey/convey"
@@ -37,6 +40,9 @@ type CinderV2Suite struct {
 	Token                                    string
 	SnapShotSize                             int
 	Tenant1ID, Tenant2ID                     string
+	Pool                                     string
+	BackupSize                               int
+	VolumesLimit                             int32
 }
 
 func (s *CinderV2Suite) SetupSuite() {
@@ -57,6 +63,12 @@ func (s *CinderV2Suite) SetupSuite() {
 	registerVolumes(s)
 	s.SnapShotSize = 5
 	registerSnapshots(s)
+	s.Pool = "cinder@lvm#lvm"
+	registerPools(s)
+	registerServices(s)
+	s.BackupSize = 3
+	registerBackups(s)
+	registerQuotaSets(s)
 }
 
 func (suite *CinderV2Suite) TearDownSuite() {
@@ -72,7 +84,7 @@ func (s *CinderV2Suite) TestGetLimits() {
 	Convey("Given Cinder absolute limits are requested", s.T(), func() {
 
 		Convey("When authentication is required", func() {
-			provider, err := openstackintel.Authenticate(th.Endpoint(), "me", "secret", "tenant", "", "")
+			provider, err := openstackintel.Authenticate(openstackintel.AuthOptions{Endpoint: th.Endpoint(), User: "me", Password: "secret"}, "tenant")
 			th.AssertNoErr(s.T(), err)
 			th.CheckEquals(s.T(), s.Token, provider.TokenID)
 
@@ -83,6 +95,55 @@ func (s *CinderV2Suite) TestGetLimits() {
 				Convey("Then proper limits values are returned", func() {
 					So(limits.MaxTotalVolumes, ShouldEqual, s.MaxTotalVolumes)
 					So(limits.MaxTotalVolumeGigabytes, ShouldEqual, s.MaxTotalVolumeGigabytes)
+					So(limits.MaxTotalSnapshots, ShouldEqual, 10)
+					So(limits.MaxTotalBackups, ShouldEqual, 10)
+					So(limits.MaxTotalBackupGigabytes, ShouldEqual, 1000)
+					So(limits.TotalVolumesUsed, ShouldEqual, 2)
+					So(limits.TotalGigabytesUsed, ShouldEqual, 4)
+					So(limits.TotalSnapshotsUsed, ShouldEqual, 5)
+					So(limits.TotalBackupsUsed, ShouldEqual, 1)
+					So(limits.TotalBackupGigabytesUsed, ShouldEqual, 3)
+				})
+
+				Convey("and no error reported", func() {
+					So(err, ShouldBeNil)
+				})
+			})
+		})
+	})
+}
+
+func (s *CinderV2Suite) TestGetQuotas() {
+	Convey("Given Cinder tenant quotas are requested", s.T(), func() {
+
+		Convey("When authentication is required", func() {
+			provider, err := openstackintel.Authenticate(openstackintel.AuthOptions{Endpoint: th.Endpoint(), User: "me", Password: "secret"}, "tenant")
+			th.AssertNoErr(s.T(), err)
+			th.CheckEquals(s.T(), s.Token, provider.TokenID)
+
+			Convey("and GetQuotas called", func() {
+				dispatch := ServiceV2{}
+				limits, err := dispatch.GetQuotas(provider, s.Tenant2ID)
+
+				Convey("Then proper limits values are returned", func() {
+					So(limits.MaxTotalVolumes, ShouldEqual, s.MaxTotalVolumes)
+					So(limits.MaxTotalVolumeGigabytes, ShouldEqual, s.MaxTotalVolumeGigabytes)
+					So(limits.MaxTotalBackupGigabytes, ShouldEqual, -1)
+					So(limits.TotalVolumesUsed, ShouldEqual, 2)
+					So(limits.TotalGigabytesUsed, ShouldEqual, 33)
+					So(limits.TotalVolumesReserved, ShouldEqual, 1)
+					So(limits.TotalGigabytesReserved, ShouldEqual, 10)
+					So(limits.Reserved, ShouldBeTrue)
+				})
+
+				Convey("and limits per volume type are returned", func() {
+					So(len(limits.Types), ShouldEqual, 2)
+					So(limits.Types["SSD"].MaxVolumes, ShouldEqual, 4)
+					So(limits.Types["SSD"].MaxGigabytes, ShouldEqual, 100)
+					So(limits.Types["SSD"].UsedVolumes, ShouldEqual, 1)
+					So(limits.Types["SSD"].UsedGigabytes, ShouldEqual, 22)
+					So(limits.Types["SSD_replicated"].MaxVolumes, ShouldEqual, 2)
+					So(limits.Types["SSD_replicated"].MaxGigabytes, ShouldEqual, 50)
 				})
 
 				Convey("and no error reported", func() {
@@ -97,7 +158,7 @@ func (s *CinderV2Suite) TestGetVolumes() {
 	Convey("Given Cinder volumes are requested", s.T(), func() {
 
 		Convey("When authentication is required", func() {
-			provider, err := openstackintel.Authenticate(th.Endpoint(), "me", "secret", "tenant", "", "")
+			provider, err := openstackintel.Authenticate(openstackintel.AuthOptions{Endpoint: th.Endpoint(), User: "me", Password: "secret"}, "tenant")
 			th.AssertNoErr(s.T(), err)
 			th.CheckEquals(s.T(), s.Token, provider.TokenID)
 
@@ -111,6 +172,11 @@ func (s *CinderV2Suite) TestGetVolumes() {
 					So(volumes[s.Tenant2ID].Bytes, ShouldEqual, s.Vol2Size*1024*1024*1024)
 					So(volumes[s.Tenant1ID].Count, ShouldEqual, 1)
 					So(volumes[s.Tenant2ID].Count, ShouldEqual, 1)
+					So(volumes[s.Tenant1ID].Status["available"].Count, ShouldEqual, 1)
+					So(volumes[s.Tenant1ID].Status["available"].Bytes, ShouldEqual, s.Vol1Size*1024*1024*1024)
+					So(volumes[s.Tenant2ID].Status["in-use"].Count, ShouldEqual, 1)
+					So(volumes[s.Tenant1ID].Types["none"].Count, ShouldEqual, 1)
+					So(volumes[s.Tenant2ID].Types["SSD"].Bytes, ShouldEqual, s.Vol2Size*1024*1024*1024)
 				})
 
 				Convey("and no error reported", func() {
@@ -121,11 +187,34 @@ func (s *CinderV2Suite) TestGetVolumes() {
 	})
 }
 
+func (s *CinderV2Suite) TestGetVolumesPageSize() {
+	Convey("Given Cinder volumes are requested with configured page size", s.T(), func() {
+		provider, err := openstackintel.Authenticate(openstackintel.AuthOptions{Endpoint: th.Endpoint(), User: "me", Password: "secret"}, "tenant")
+		th.AssertNoErr(s.T(), err)
+
+		Convey("When GetVolumes called", func() {
+			dispatch := ServiceV2{PageSize: 1}
+			volumes, err := dispatch.GetVolumes(provider)
+
+			Convey("Then page size is sent with every page request", func() {
+				So(err, ShouldBeNil)
+				So(atomic.LoadInt32(&s.VolumesLimit), ShouldEqual, 1)
+			})
+
+			Convey("and volumes from all pages are aggregated", func() {
+				So(len(volumes), ShouldEqual, 2)
+				So(volumes[s.Tenant1ID].Count, ShouldEqual, 1)
+				So(volumes[s.Tenant2ID].Count, ShouldEqual, 1)
+			})
+		})
+	})
+}
+
 func (s *CinderV2Suite) TestGetSnapshots() {
 	Convey("Given Cinder snapshots are requested", s.T(), func() {
 
 		Convey("When authentication is required", func() {
-			provider, err := openstackintel.Authenticate(th.Endpoint(), "me", "secret", "tenant", "", "")
+			provider, err := openstackintel.Authenticate(openstackintel.AuthOptions{Endpoint: th.Endpoint(), User: "me", Password: "secret"}, "tenant")
 			th.AssertNoErr(s.T(), err)
 			th.CheckEquals(s.T(), s.Token, provider.TokenID)
 
@@ -137,6 +226,109 @@ func (s *CinderV2Suite) TestGetSnapshots() {
 					So(len(snapshots), ShouldEqual, 1)
 					So(snapshots[s.Tenant1ID].Count, ShouldEqual, 1)
 					So(snapshots[s.Tenant1ID].Bytes, ShouldEqual, s.SnapShotSize*1024*1024*1024)
+					So(snapshots[s.Tenant1ID].Status["available"].Count, ShouldEqual, 1)
+					So(snapshots[s.Tenant1ID].Status["available"].Bytes, ShouldEqual, s.SnapShotSize*1024*1024*1024)
+				})
+
+				Convey("and no error reported", func() {
+					So(err, ShouldBeNil)
+				})
+			})
+		})
+	})
+}
+
+func (s *CinderV2Suite) TestGetPools() {
+	Convey("Given Cinder backend pools are requested", s.T(), func() {
+
+		Convey("When authentication is required", func() {
+			provider, err := openstackintel.Authenticate(openstackintel.AuthOptions{Endpoint: th.Endpoint(), User: "me", Password: "secret"}, "tenant")
+			th.AssertNoErr(s.T(), err)
+			th.CheckEquals(s.T(), s.Token, provider.TokenID)
+
+			Convey("and GetPools called", func() {
+				dispatch := ServiceV2{}
+				pools, err := dispatch.GetPools(provider)
+
+				Convey("Then proper pools capacity values are returned", func() {
+					So(len(pools), ShouldEqual, 2)
+					So(pools[s.Pool].TotalCapacityGB, ShouldEqual, 100.5)
+					So(pools[s.Pool].FreeCapacityGB, ShouldEqual, 60)
+					So(pools[s.Pool].AllocatedCapacityGB, ShouldEqual, 40)
+					So(pools[s.Pool].ProvisionedCapacityGB, ShouldEqual, 45)
+					So(pools[s.Pool].MaxOverSubscriptionRatio, ShouldEqual, 20)
+					So(pools[s.Pool].ReservedPercentage, ShouldEqual, 5)
+					So(pools["cinder@rbd#rbd"].TotalCapacityGB, ShouldEqual, -1)
+					So(pools["cinder@rbd#rbd"].FreeCapacityGB, ShouldEqual, -1)
+					So(pools["cinder@rbd#rbd"].ProvisionedCapacityGB, ShouldEqual, -1)
+					So(pools["cinder@rbd#rbd"].ReservedPercentage, ShouldEqual, 0)
+				})
+
+				Convey("and no error reported", func() {
+					So(err, ShouldBeNil)
+				})
+			})
+		})
+	})
+}
+
+func (s *CinderV2Suite) TestGetServices() {
+	Convey("Given Cinder services are requested", s.T(), func() {
+
+		Convey("When authentication is required", func() {
+			provider, err := openstackintel.Authenticate(openstackintel.AuthOptions{Endpoint: th.Endpoint(), User: "me", Password: "secret"}, "tenant")
+			th.AssertNoErr(s.T(), err)
+			th.CheckEquals(s.T(), s.Token, provider.TokenID)
+
+			Convey("and GetServices called", func() {
+				dispatch := ServiceV2{}
+				services, err := dispatch.GetServices(provider)
+
+				Convey("Then proper services health values are returned", func() {
+					So(len(services), ShouldEqual, 2)
+					So(len(services["cinder-volume"]), ShouldEqual, 2)
+
+					scheduler := services["cinder-scheduler"]["controller"]
+					So(scheduler.State, ShouldEqual, 1)
+					So(scheduler.Status, ShouldEqual, 1)
+					So(scheduler.UpdatedSecondsAgo, ShouldBeGreaterThan, 0)
+
+					volume := services["cinder-volume"]["controller@lvm"]
+					So(volume.State, ShouldEqual, 0)
+					So(volume.Status, ShouldEqual, 0)
+
+					So(services["cinder-volume"]["controller@rbd"].UpdatedSecondsAgo, ShouldEqual, -1)
+				})
+
+				Convey("and no error reported", func() {
+					So(err, ShouldBeNil)
+				})
+			})
+		})
+	})
+}
+
+func (s *CinderV2Suite) TestGetBackups() {
+	Convey("Given Cinder backups are requested", s.T(), func() {
+
+		Convey("When authentication is required", func() {
+			provider, err := openstackintel.Authenticate(openstackintel.AuthOptions{Endpoint: th.Endpoint(), User: "me", Password: "secret"}, "tenant")
+			th.AssertNoErr(s.T(), err)
+			th.CheckEquals(s.T(), s.Token, provider.TokenID)
+
+			Convey("and GetBackups called", func() {
+				dispatch := ServiceV2{}
+				backups, err := dispatch.GetBackups(provider)
+
+				Convey("Then proper backups values of all pages are returned", func() {
+					So(len(backups), ShouldEqual, 2)
+					So(backups[s.Tenant1ID].Count, ShouldEqual, 2)
+					So(backups[s.Tenant1ID].Bytes, ShouldEqual, 2*s.BackupSize*1024*1024*1024)
+					So(backups[s.Tenant1ID].Status["available"].Count, ShouldEqual, 1)
+					So(backups[s.Tenant1ID].Status["error"].Count, ShouldEqual, 1)
+					So(backups[s.Tenant1ID].NewestAvailable, ShouldResemble, time.Date(2016, 2, 20, 10, 0, 0, 0, time.UTC))
+					So(backups[s.Tenant2ID].Count, ShouldEqual, 1)
+					So(backups[s.Tenant2ID].NewestAvailable, ShouldResemble, time.Date(2016, 2, 19, 10, 0, 0, 0, time.UTC))
 				})
 
 				Convey("and no error reported", func() {
@@ -267,6 +459,34 @@ func registerLimits(s *CinderV2Suite) {
 	})
 }
 
+func registerQuotaSets(s *CinderV2Suite) {
+	th.Mux.HandleFunc("/v2/v2ffff/os-quota-sets/"+s.Tenant2ID, func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(s.T(), r, "GET")
+		th.TestHeader(s.T(), r, "X-Auth-Token", s.Token)
+		th.TestFormValues(s.T(), r, map[string]string{"usage": "true"})
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		fmt.Fprintf(w, `
+			{
+				"quota_set": {
+					"id": "%s",
+					"volumes": {"limit": %d, "in_use": 2, "reserved": 1},
+					"gigabytes": {"limit": %d, "in_use": 33, "reserved": 10},
+					"snapshots": {"limit": 10, "in_use": 1, "reserved": 0},
+					"backups": {"limit": 10, "in_use": 0, "reserved": 0},
+					"backup_gigabytes": {"limit": -1, "in_use": 0, "reserved": 0},
+					"volumes_SSD": {"limit": 4, "in_use": 1, "reserved": 0},
+					"gigabytes_SSD": {"limit": 100, "in_use": 22, "reserved": 0},
+					"snapshots_SSD": {"limit": -1, "in_use": 0, "reserved": 0},
+					"volumes_SSD replicated": {"limit": 2, "in_use": 0, "reserved": 0},
+					"gigabytes_SSD replicated": {"limit": 50, "in_use": 0, "reserved": 0}
+				}
+			}
+		`, s.Tenant2ID, s.MaxTotalVolumes, s.MaxTotalVolumeGigabytes)
+	})
+}
+
 func registerVolumes(s *CinderV2Suite) {
 	url := "/v2/v2ffff/volumes/detail" //?all_tenants=true
 	th.Mux.HandleFunc(url, func(w http.ResponseWriter, r *http.Request) {
@@ -276,7 +496,17 @@ func registerVolumes(s *CinderV2Suite) {
 		w.Header().Add("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 
-		fmt.Fprintf(w, `
+		// volumes are listed one per page, following page is linked with marker of last volume
+		limit, _ := strconv.Atoi(r.FormValue("limit"))
+		atomic.StoreInt32(&s.VolumesLimit, int32(limit))
+		next := th.Endpoint() + url[1:] + "?all_tenants=true&marker=" + s.Vol1
+		if limit > 0 {
+			next += "&limit=" + strconv.Itoa(limit)
+		}
+
+		switch r.FormValue("marker") {
+		case "":
+			fmt.Fprintf(w, `
 			{
 				"volumes": [
 					{
@@ -324,7 +554,20 @@ func registerVolumes(s *CinderV2Suite) {
 							"size": "13287936"
 						},
 						"volume_type": null
-					},
+					}
+				],
+				"volumes_links": [
+					{
+						"href": "%s",
+						"rel": "next"
+					}
+				]
+			}
+		`, s.Vol1, s.Tenant1ID, s.Vol1Size, next)
+		case s.Vol1:
+			fmt.Fprintf(w, `
+			{
+				"volumes": [
 					{
 						"attachments": [],
 						"availability_zone": "nova",
@@ -357,7 +600,7 @@ func registerVolumes(s *CinderV2Suite) {
 						"size": %d,
 						"snapshot_id": null,
 						"source_volid": null,
-						"status": "available",
+						"status": "in-use",
 						"user_id": "a3edd7a918fc4373981051c975295dc8",
 						"volume_image_metadata": {
 							"checksum": "ee1eca47dc88f4879d8a229cc70a07c6",
@@ -369,11 +612,14 @@ func registerVolumes(s *CinderV2Suite) {
 							"min_ram": "64",
 							"size": "13287936"
 						},
-						"volume_type": null
+						"volume_type": "SSD"
 					}
-    			]
-       		 }
-		`, s.Vol1, s.Tenant1ID, s.Vol1Size, s.Vol2, s.Tenant2ID, s.Vol2Size)
+				]
+			}
+		`, s.Vol2, s.Tenant2ID, s.Vol2Size)
+		default:
+			fmt.Fprintf(w, `{"volumes": []}`)
+		}
 	})
 }
 
@@ -386,6 +632,12 @@ func registerSnapshots(s *CinderV2Suite) {
 		w.Header().Add("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 
+		// last page is full, so it links to following empty page
+		if r.FormValue("marker") != "" {
+			fmt.Fprintf(w, `{"snapshots": []}`)
+			return
+		}
+
 		fmt.Fprintf(w, `
 			{
 				"snapshots": [
@@ -401,8 +653,163 @@ func registerSnapshots(s *CinderV2Suite) {
 						"status": "available",
 						"volume_id": "495a1698-ca2f-4e84-8d34-fa544c65ae3d"
 					}
+				],
+				"snapshots_links": [
+					{
+						"href": "%s",
+						"rel": "next"
+					}
+				]
+			}
+		`, s.Tenant1ID, s.SnapShotSize, th.Endpoint()+snapshots[1:]+"?all_tenants=true&marker=snap1cccc")
+	})
+}
+
+func registerPools(s *CinderV2Suite) {
+	th.Mux.HandleFunc("/v2/v2ffff/scheduler-stats/get_pools", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(s.T(), r, "GET")
+		th.TestHeader(s.T(), r, "X-Auth-Token", s.Token)
+		th.TestFormValues(s.T(), r, map[string]string{"detail": "true"})
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		fmt.Fprintf(w, `
+			{
+				"pools": [
+					{
+						"name": "%s",
+						"capabilities": {
+							"pool_name": "lvm",
+							"total_capacity_gb": 100.5,
+							"free_capacity_gb": 60,
+							"allocated_capacity_gb": 40,
+							"provisioned_capacity_gb": 45,
+							"max_over_subscription_ratio": "20.0",
+							"reserved_percentage": 5,
+							"volume_backend_name": "lvm"
+						}
+					},
+					{
+						"name": "cinder@rbd#rbd",
+						"capabilities": {
+							"pool_name": "rbd",
+							"total_capacity_gb": "infinite",
+							"free_capacity_gb": "unknown",
+							"reserved_percentage": 0,
+							"volume_backend_name": "rbd"
+						}
+					}
+				]
+			}
+		`, s.Pool)
+	})
+}
+
+func registerServices(s *CinderV2Suite) {
+	th.Mux.HandleFunc("/v2/v2ffff/os-services", func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(s.T(), r, "GET")
+		th.TestHeader(s.T(), r, "X-Auth-Token", s.Token)
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		fmt.Fprintf(w, `
+			{
+				"services": [
+					{
+						"binary": "cinder-scheduler",
+						"host": "controller",
+						"state": "up",
+						"status": "enabled",
+						"updated_at": "2016-02-21T13:28:30.000000",
+						"zone": "nova"
+					},
+					{
+						"binary": "cinder-volume",
+						"host": "controller@lvm",
+						"state": "down",
+						"status": "disabled",
+						"updated_at": "2016-02-21T13:28:30.000000",
+						"zone": "nova"
+					},
+					{
+						"binary": "cinder-volume",
+						"host": "controller@rbd",
+						"state": "down",
+						"status": "enabled",
+						"updated_at": null,
+						"zone": "nova"
+					}
+				]
+			}
+		`)
+	})
+}
+
+func registerBackups(s *CinderV2Suite) {
+	backups := "/v2/v2ffff/backups/detail"
+	th.Mux.HandleFunc(backups, func(w http.ResponseWriter, r *http.Request) {
+		th.TestMethod(s.T(), r, "GET")
+		th.TestHeader(s.T(), r, "X-Auth-Token", s.Token)
+		th.TestFormValues(s.T(), r, map[string]string{"all_tenants": "true"})
+		w.Header().Add("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		// backup with project is listed on following page linked with marker of last backup
+		if r.FormValue("marker") == "backup2" {
+			fmt.Fprintf(w, `
+				{
+					"backups": [
+						{
+							"availability_zone": "nova",
+							"container": "volumebackups",
+							"created_at": "2016-02-19T10:00:00.000000",
+							"description": null,
+							"id": "backup3",
+							"name": "backup_3",
+							"os-backup-project-attr:project_id": "%s",
+							"size": %d,
+							"status": "available",
+							"volume_id": "deleted_volume"
+						}
+					]
+				}
+			`, s.Tenant2ID, s.BackupSize)
+			return
+		}
+
+		fmt.Fprintf(w, `
+			{
+				"backups": [
+					{
+						"availability_zone": "nova",
+						"container": "volumebackups",
+						"created_at": "2016-02-20T10:00:00.000000",
+						"description": null,
+						"id": "backup1",
+						"name": "backup_1",
+						"size": %d,
+						"status": "available",
+						"volume_id": "%s"
+					},
+					{
+						"availability_zone": "nova",
+						"container": "volumebackups",
+						"created_at": "2016-02-21T10:00:00.000000",
+						"description": null,
+						"id": "backup2",
+						"name": "backup_2",
+						"size": %d,
+						"status": "error",
+						"volume_id": "%s"
+					}
+				],
+				"backups_links": [
+					{
+						"href": "%s",
+						"rel": "next"
+					}
 				]
 			}
-		`, s.Tenant1ID, s.SnapShotSize)
+		`, s.BackupSize, s.Vol1, s.BackupSize, s.Vol1, th.Endpoint()+backups[1:]+"?all_tenants=true&marker=backup2")
 	})
 }
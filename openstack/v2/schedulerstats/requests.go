@@ -0,0 +1,59 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// requests contains Cinder API requests for scheduler statistics
+
+package schedulerstats
+
+import (
+	"github.com/rackspace/gophercloud"
+	"github.com/rackspace/gophercloud/pagination"
+)
+
+// ListPoolsOptsBuilder allows extensions to add additional parameters to the ListPools request.
+type ListPoolsOptsBuilder interface {
+	ToPoolsListQuery() (string, error)
+}
+
+// ListPoolsOpts holds options for listing backend pools. It is passed to the schedulerstats.ListPools function.
+type ListPoolsOpts struct {
+	// Set it to true to get pool capabilities along with pool names.
+	Detail bool `q:"detail"`
+}
+
+// ToPoolsListQuery formats a ListPoolsOpts into a query string.
+func (opts ListPoolsOpts) ToPoolsListQuery() (string, error) {
+	q, err := gophercloud.BuildQueryString(opts)
+	if err != nil {
+		return "", err
+	}
+	return q.String(), nil
+}
+
+// ListPools returns backend pools known to Cinder scheduler. This is admin-only call.
+func ListPools(client *gophercloud.ServiceClient, opts ListPoolsOptsBuilder) pagination.Pager {
+	url := listPoolsURL(client)
+	if opts != nil {
+		query, err := opts.ToPoolsListQuery()
+		if err != nil {
+			return pagination.Pager{Err: err}
+		}
+		url += query
+	}
+
+	createPage := func(r pagination.PageResult) pagination.Page {
+		return PoolsPage{pagination.SinglePageBase(r)}
+	}
+	return pagination.NewPager(client, url, createPage)
+}
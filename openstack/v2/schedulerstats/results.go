@@ -0,0 +1,71 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// results contains Cinder API responses and their processing for scheduler statistics
+
+package schedulerstats
+
+import (
+	"strconv"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/rackspace/gophercloud/pagination"
+)
+
+// Pool contains information about Cinder backend pool reported by scheduler
+type Pool struct {
+	Name         string                 `mapstructure:"name"`
+	Capabilities map[string]interface{} `mapstructure:"capabilities"`
+}
+
+// Capacity returns numeric value of given pool capability
+// Backends report "infinite" or "unknown" when capacity cannot be determined, and some drivers do not report
+// capability at all, -1 is returned then so that unknown capacity is not mistaken for empty pool
+func (p Pool) Capacity(key string) float64 {
+	switch value := p.Capabilities[key].(type) {
+	case float64:
+		return value
+	case string:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return -1
+		}
+		return parsed
+	}
+	return -1
+}
+
+// PoolsPage is a pagination.Pager that is returned from a call to the ListPools function.
+type PoolsPage struct {
+	pagination.SinglePageBase
+}
+
+// IsEmpty returns true if a PoolsPage contains no Pools.
+func (r PoolsPage) IsEmpty() (bool, error) {
+	pools, err := ExtractPools(r)
+	if err != nil {
+		return true, err
+	}
+	return len(pools) == 0, nil
+}
+
+// ExtractPools extracts and returns Pools. It is used while iterating over a schedulerstats.ListPools call.
+func ExtractPools(page pagination.Page) ([]Pool, error) {
+	var response struct {
+		Pools []Pool `mapstructure:"pools"`
+	}
+
+	err := mapstructure.Decode(page.(PoolsPage).Body, &response)
+	return response.Pools, err
+}
@@ -32,6 +32,10 @@ specific language governing permissions and limitations under the License.
 // Package contains code from Rackspace Gophercloud (https://github.com/rackspace/gophercloud) with following changes:
 // - structure ListOpts:
 //   - added AllTenants field
+//   - added Limit field
+//   - added Marker field
+//
+// - List returns pages linked with snapshots_links
 package snapshots
 
 import (
@@ -52,6 +56,8 @@ type ListOpts struct {
 	Status     string `q:"status"`
 	VolumeID   string `q:"volume_id"`
 	AllTenants bool   `q:"all_tenants"`
+	Limit      int    `q:"limit"`
+	Marker     string `q:"marker"`
 }
 
 // ToSnapshotListQuery formats a ListOpts into a query string.
@@ -76,7 +82,7 @@ func List(client *gophercloud.ServiceClient, opts ListOptsBuilder) pagination.Pa
 	}
 
 	createPage := func(r pagination.PageResult) pagination.Page {
-		return ListResult{pagination.SinglePageBase(r)}
+		return ListResult{pagination.LinkedPageBase{PageResult: r}}
 	}
 	return pagination.NewPager(client, url, createPage)
 }
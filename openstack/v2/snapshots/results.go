@@ -41,6 +41,10 @@ specific language governing permissions and limitations under the License.
 //   - removed original field comments
 //   - added OsExtendedSnapshotAttributesProgress field
 //   - added OsExtendedSnapshotAttributesProjectID field
+//
+// - ListResult structure:
+//   - embedded LinkedPageBase instead of SinglePageBase
+//   - added NextPageURL method
 package snapshots
 
 import (
@@ -70,7 +74,28 @@ type GetResult struct {
 
 // ListResult is a pagination.Pager that is returned from a call to the List function.
 type ListResult struct {
-	pagination.SinglePageBase
+	pagination.LinkedPageBase
+}
+
+// NextPageURL returns URL of the next page taken from snapshots_links, it is empty for the last page
+func (r ListResult) NextPageURL() (string, error) {
+	var response struct {
+		Links []struct {
+			Href string `mapstructure:"href"`
+			Rel  string `mapstructure:"rel"`
+		} `mapstructure:"snapshots_links"`
+	}
+
+	if err := mapstructure.Decode(r.Body, &response); err != nil {
+		return "", err
+	}
+
+	for _, link := range response.Links {
+		if link.Rel == "next" {
+			return link.Href, nil
+		}
+	}
+	return "", nil
 }
 
 // IsEmpty returns true if a ListResult contains no Snapshots.
@@ -29,7 +29,12 @@ CONDITIONS OF ANY KIND, either express or implied.  See the License for the
 specific language governing permissions and limitations under the License.
 */
 
-// Package contains code from Rackspace Gophercloud (https://github.com/rackspace/gophercloud)
+// Package contains code from Rackspace Gophercloud (https://github.com/rackspace/gophercloud) with following changes:
+// - structure ListOpts:
+//   - added Limit field
+//   - added Marker field
+//
+// - List returns pages linked with volumes_links
 package volumes
 
 import (
@@ -63,6 +68,10 @@ type ListOpts struct {
 	Name string `q:"name"`
 	// List only volumes that have a status of Status.
 	Status string `q:"status"`
+	// Maximum number of volumes returned in single page, Cinder default is used when not set.
+	Limit int `q:"limit"`
+	// List only volumes after volume with ID of Marker.
+	Marker string `q:"marker"`
 }
 
 // List returns Volumes optionally limited by the conditions provided in ListOpts.
@@ -76,7 +85,7 @@ func List(client *gophercloud.ServiceClient, opts ListOptsBuilder) pagination.Pa
 		url += query
 	}
 	createPage := func(r pagination.PageResult) pagination.Page {
-		return ListResult{pagination.SinglePageBase(r)}
+		return ListResult{pagination.LinkedPageBase{PageResult: r}}
 	}
 
 	return pagination.NewPager(client, url, createPage)
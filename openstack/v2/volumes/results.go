@@ -40,6 +40,10 @@ specific language governing permissions and limitations under the License.
 //   - added OsVolTenantAttrTenantID field
 //   - added OsVolumeReplicationDriverData field
 //   - added OsVolumeReplicationExtendedStatus field
+//
+// - ListResult structure:
+//   - embedded LinkedPageBase instead of SinglePageBase
+//   - added NextPageURL method
 package volumes
 
 import (
@@ -136,7 +140,28 @@ type GetResult struct {
 
 // ListMetaResult is a pagination.pager that is returned from a call to the ListMeta function.
 type ListResult struct {
-	pagination.SinglePageBase
+	pagination.LinkedPageBase
+}
+
+// NextPageURL returns URL of the next page taken from volumes_links, it is empty for the last page
+func (r ListResult) NextPageURL() (string, error) {
+	var response struct {
+		Links []struct {
+			Href string `mapstructure:"href"`
+			Rel  string `mapstructure:"rel"`
+		} `mapstructure:"volumes_links"`
+	}
+
+	if err := mapstructure.Decode(r.Body, &response); err != nil {
+		return "", err
+	}
+
+	for _, link := range response.Links {
+		if link.Rel == "next" {
+			return link.Href, nil
+		}
+	}
+	return "", nil
 }
 
 // IsEmpty returns true if a ListResult contains no Volumes.
@@ -0,0 +1,44 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Cinder package contains wrapper functions designed to collect required metrics
+// All functions are dependant on OpenStack BlockStorage API Version 3
+
+package cinder
+
+import (
+	"github.com/rackspace/gophercloud"
+
+	cinderv2 "github.com/intelsdi-x/snap-plugin-collector-cinder/openstack/v2/cinder"
+	openstackintel "github.com/intelsdi-x/snap-plugin-collector-cinder/openstack/v3"
+)
+
+// ServiceV3 serves as dispatcher for Cinder API version 3.0
+// Cinder API version 3.0 is compatible with version 2.0, so the same calls are sent to volumev3 endpoint
+type ServiceV3 struct {
+	cinderv2.ServiceV2
+	// Microversion is sent with every request, empty if Cinder does not support microversions
+	Microversion string
+}
+
+// NewServiceV3 creates dispatcher for Cinder API version 3.0 using given microversion
+func NewServiceV3(microversion string) ServiceV3 {
+	newClient := func(provider *gophercloud.ProviderClient, eo gophercloud.EndpointOpts) (*gophercloud.ServiceClient, error) {
+		return openstackintel.NewBlockStorageV3(provider, eo, microversion)
+	}
+	return ServiceV3{
+		ServiceV2:    cinderv2.ServiceV2{NewClient: newClient},
+		Microversion: microversion,
+	}
+}
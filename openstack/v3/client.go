@@ -0,0 +1,147 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v3
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rackspace/gophercloud"
+)
+
+// MaxMicroversion is the newest Cinder API microversion used by plugin
+// Microversion 3.18 reports project of volume backups
+const MaxMicroversion = "3.18"
+
+// NewBlockStorageV3 creates a ServiceClient that may be used with Cinder API version 3.
+// Requests are sent with OpenStack-API-Version header set to given microversion, unless it is empty.
+func NewBlockStorageV3(client *gophercloud.ProviderClient, eo gophercloud.EndpointOpts, microversion string) (*gophercloud.ServiceClient, error) {
+	eo.ApplyDefaults("volumev3")
+	url, err := client.EndpointLocator(eo)
+	if err != nil {
+		return nil, err
+	}
+	if microversion == "" {
+		return &gophercloud.ServiceClient{ProviderClient: client, Endpoint: url}, nil
+	}
+
+	// provider is copied to send microversion header only with Cinder requests
+	provider := *client
+	provider.HTTPClient.Transport = microversionTransport{base: client.HTTPClient.Transport, microversion: microversion}
+	if client.ReauthFunc != nil {
+		provider.ReauthFunc = func() error {
+			if err := client.ReauthFunc(); err != nil {
+				return err
+			}
+			provider.TokenID = client.TokenID
+			return nil
+		}
+	}
+
+	return &gophercloud.ServiceClient{ProviderClient: &provider, Endpoint: url}, nil
+}
+
+// microversionTransport sets OpenStack-API-Version header of every request
+type microversionTransport struct {
+	base         http.RoundTripper
+	microversion string
+}
+
+// RoundTrip sends request with microversion header using base transport
+func (t microversionTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	// request must not be modified by transport
+	req := new(http.Request)
+	*req = *r
+	req.Header = http.Header{}
+	for key, values := range r.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("OpenStack-API-Version", "volume "+t.microversion)
+
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
+
+// NegotiateMicroversion chooses the newest microversion supported both by Cinder and plugin
+// Cinder supported microversions are given as range from minVersion to maxVersion, empty if microversions are not supported
+func NegotiateMicroversion(minVersion, maxVersion string) (string, error) {
+	if maxVersion == "" {
+		return "", nil
+	}
+
+	chosen := MaxMicroversion
+	newer, err := compareMicroversions(chosen, maxVersion)
+	if err != nil {
+		return "", err
+	}
+	if newer > 0 {
+		chosen = maxVersion
+	}
+
+	if minVersion != "" {
+		older, err := compareMicroversions(chosen, minVersion)
+		if err != nil {
+			return "", err
+		}
+		if older < 0 {
+			return "", fmt.Errorf("Cinder API microversions %s - %s are not supported, the newest supported is %s", minVersion, maxVersion, MaxMicroversion)
+		}
+	}
+
+	return chosen, nil
+}
+
+// compareMicroversions returns -1, 0 or 1 if microversion a is respectively older, the same or newer than b
+func compareMicroversions(a, b string) (int, error) {
+	aMajor, aMinor, err := parseMicroversion(a)
+	if err != nil {
+		return 0, err
+	}
+	bMajor, bMinor, err := parseMicroversion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case aMajor < bMajor || (aMajor == bMajor && aMinor < bMinor):
+		return -1, nil
+	case aMajor == bMajor && aMinor == bMinor:
+		return 0, nil
+	default:
+		return 1, nil
+	}
+}
+
+// parseMicroversion splits microversion given as <major>.<minor> (e.g. 3.18)
+func parseMicroversion(microversion string) (int, int, error) {
+	parts := strings.Split(microversion, ".")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("Incorrect microversion %s", microversion)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("Incorrect microversion %s", microversion)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("Incorrect microversion %s", microversion)
+	}
+	return major, minor, nil
+}
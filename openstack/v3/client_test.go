@@ -0,0 +1,128 @@
+// +build small
+
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v3
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/rackspace/gophercloud"
+	th "github.com/rackspace/gophercloud/testhelper"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNewBlockStorageV3(t *testing.T) {
+	Convey("Given provider client for Cinder endpoint", t, func() {
+		th.SetupHTTP()
+		defer th.TeardownHTTP()
+
+		provider := &gophercloud.ProviderClient{
+			TokenID: "2ed210f132564f21b178afb197ee99e3",
+			EndpointLocator: func(eo gophercloud.EndpointOpts) (string, error) {
+				return th.Endpoint() + "v3/v3ffff/", nil
+			},
+		}
+
+		microversions := []string{}
+		th.Mux.HandleFunc("/v3/v3ffff/limits", func(w http.ResponseWriter, r *http.Request) {
+			microversions = append(microversions, r.Header.Get("OpenStack-API-Version"))
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{}`))
+		})
+
+		Convey("When client is created with microversion", func() {
+			client, err := NewBlockStorageV3(provider, gophercloud.EndpointOpts{}, "3.18")
+			So(err, ShouldBeNil)
+
+			var body interface{}
+			_, err = client.Get(client.ServiceURL("limits"), &body, nil)
+
+			Convey("Then microversion header is sent with request", func() {
+				So(err, ShouldBeNil)
+				So(microversions, ShouldResemble, []string{"volume 3.18"})
+			})
+
+			Convey("and provider client is not modified", func() {
+				_, err = provider.Request("GET", th.Endpoint()+"v3/v3ffff/limits", gophercloud.RequestOpts{JSONResponse: &body})
+				So(err, ShouldBeNil)
+				So(microversions[1], ShouldEqual, "")
+			})
+		})
+
+		Convey("When client is created without microversion", func() {
+			client, err := NewBlockStorageV3(provider, gophercloud.EndpointOpts{}, "")
+			So(err, ShouldBeNil)
+
+			var body interface{}
+			_, err = client.Get(client.ServiceURL("limits"), &body, nil)
+
+			Convey("Then no microversion header is sent", func() {
+				So(err, ShouldBeNil)
+				So(microversions, ShouldResemble, []string{""})
+			})
+		})
+	})
+}
+
+func TestNegotiateMicroversion(t *testing.T) {
+	Convey("Given range of microversions supported by Cinder", t, func() {
+
+		Convey("When Cinder supports newer microversions", func() {
+			microversion, err := NegotiateMicroversion("3.0", "3.59")
+
+			Convey("Then the newest microversion supported by plugin is chosen", func() {
+				So(err, ShouldBeNil)
+				So(microversion, ShouldEqual, MaxMicroversion)
+			})
+		})
+
+		Convey("When Cinder supports only older microversions", func() {
+			microversion, err := NegotiateMicroversion("3.0", "3.9")
+
+			Convey("Then the newest microversion supported by Cinder is chosen", func() {
+				So(err, ShouldBeNil)
+				So(microversion, ShouldEqual, "3.9")
+			})
+		})
+
+		Convey("When Cinder does not support microversions", func() {
+			microversion, err := NegotiateMicroversion("", "")
+
+			Convey("Then no microversion is chosen", func() {
+				So(err, ShouldBeNil)
+				So(microversion, ShouldEqual, "")
+			})
+		})
+
+		Convey("When Cinder requires newer microversion", func() {
+			_, err := NegotiateMicroversion("3.40", "3.59")
+
+			Convey("Then error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When microversion is incorrect", func() {
+			_, err := NegotiateMicroversion("3.0", "latest")
+
+			Convey("Then error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
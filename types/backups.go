@@ -0,0 +1,90 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+import "time"
+
+// BackupStatuses lists backup statuses reported by Cinder
+var BackupStatuses = []string{
+	"creating", "available", "deleting", "error", "restoring", "error_restoring", "error_deleting",
+}
+
+// Backups represents cinder volume backups metric
+// Count - total number of backups counted
+// Bytes - total number of bytes counted
+type Backups struct {
+	Count uint `json:"count"`
+	Bytes int  `json:"bytes"`
+}
+
+// TenantBackups represents cinder volume backups metrics of single tenant
+// Backups - totals for all tenant backups
+// Status - totals for tenant backups per backup status
+// NewestAvailable - creation time of the newest successful (available) backup
+type TenantBackups struct {
+	Backups
+	Status          map[string]Backups
+	NewestAvailable time.Time
+}
+
+// Add counts backup of given size in GB, status and creation time
+func (t *TenantBackups) Add(size int, status string, created time.Time) {
+	bytes := size * 1024 * 1024 * 1024
+
+	t.Count++
+	t.Bytes += bytes
+
+	if t.Status == nil {
+		t.Status = map[string]Backups{}
+	}
+	status = knownStatus(status, BackupStatuses)
+	byStatus := t.Status[status]
+	byStatus.Count++
+	byStatus.Bytes += bytes
+	t.Status[status] = byStatus
+
+	if status == "available" && created.After(t.NewestAvailable) {
+		t.NewestAvailable = created
+	}
+}
+
+// Merge adds backups counted for other tenant
+func (t *TenantBackups) Merge(other TenantBackups) {
+	t.Count += other.Count
+	t.Bytes += other.Bytes
+
+	if t.Status == nil {
+		t.Status = map[string]Backups{}
+	}
+	for status, backups := range other.Status {
+		byStatus := t.Status[status]
+		byStatus.Count += backups.Count
+		byStatus.Bytes += backups.Bytes
+		t.Status[status] = byStatus
+	}
+
+	if other.NewestAvailable.After(t.NewestAvailable) {
+		t.NewestAvailable = other.NewestAvailable
+	}
+}
+
+// NewestAvailableAge returns number of seconds elapsed since the newest successful backup was created
+// It returns -1 if tenant has no successful backups
+func (t TenantBackups) NewestAvailableAge(now time.Time) float64 {
+	if t.NewestAvailable.IsZero() {
+		return -1
+	}
+	return now.Sub(t.NewestAvailable).Seconds()
+}
@@ -15,7 +15,50 @@ limitations under the License.
 package types
 
 // Limits represent cinder quota metrics
+// Values equal to -1 mean that given quota is unlimited
 type Limits struct {
-	MaxTotalVolumeGigabytes int `json:"MaxTotalVolumeGigabytes"`
-	MaxTotalVolumes         int `json:"MaxTotalVolumes"`
+	MaxTotalVolumeGigabytes  int `json:"MaxTotalVolumeGigabytes"`
+	MaxTotalVolumes          int `json:"MaxTotalVolumes"`
+	MaxTotalSnapshots        int `json:"MaxTotalSnapshots"`
+	MaxTotalBackups          int `json:"MaxTotalBackups"`
+	MaxTotalBackupGigabytes  int `json:"MaxTotalBackupGigabytes"`
+	TotalVolumesUsed         int `json:"TotalVolumesUsed"`
+	TotalGigabytesUsed       int `json:"TotalGigabytesUsed"`
+	TotalSnapshotsUsed       int `json:"TotalSnapshotsUsed"`
+	TotalBackupsUsed         int `json:"TotalBackupsUsed"`
+	TotalBackupGigabytesUsed int `json:"TotalBackupGigabytesUsed"`
+	// Reserved values are known only when quotas are read using admin API, see TenantLimits.Reserved
+	TotalVolumesReserved         int `json:"TotalVolumesReserved"`
+	TotalGigabytesReserved       int `json:"TotalGigabytesReserved"`
+	TotalSnapshotsReserved       int `json:"TotalSnapshotsReserved"`
+	TotalBackupsReserved         int `json:"TotalBackupsReserved"`
+	TotalBackupGigabytesReserved int `json:"TotalBackupGigabytesReserved"`
+}
+
+// VolumeTypeLimits represents cinder quota metrics of single volume type
+// Values equal to -1 mean that given quota is unlimited
+type VolumeTypeLimits struct {
+	MaxVolumes    int `json:"max_volumes"`
+	MaxGigabytes  int `json:"max_gigabytes"`
+	UsedVolumes   int `json:"used_volumes"`
+	UsedGigabytes int `json:"used_gigabytes"`
+}
+
+// TenantLimits represents cinder quota metrics of single tenant
+// Limits - tenant quotas for all volume types
+// Types - tenant quotas per volume type, reported only when quotas are read using admin API
+// Reserved - whether reserved values are known, i.e. quotas were read using admin API
+type TenantLimits struct {
+	Limits
+	Types    map[string]VolumeTypeLimits
+	Reserved bool
+}
+
+// QuotaUtilization represents percentage of tenant quota in use
+// Quotas which are unlimited (-1) or equal to 0 have no utilization and are not reported
+type QuotaUtilization struct {
+	Volumes   float64 `json:"volumes"`
+	Gigabytes float64 `json:"gigabytes"`
+	Snapshots float64 `json:"snapshots"`
+	Backups   float64 `json:"backups"`
 }
@@ -0,0 +1,26 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+// Pool represents cinder backend pool capacity metrics
+// Capacities which backend reports as infinite or unknown, or does not report at all, are represented by -1
+type Pool struct {
+	TotalCapacityGB          float64 `json:"total_capacity_gb"`
+	FreeCapacityGB           float64 `json:"free_capacity_gb"`
+	AllocatedCapacityGB      float64 `json:"allocated_capacity_gb"`
+	ProvisionedCapacityGB    float64 `json:"provisioned_capacity_gb"`
+	MaxOverSubscriptionRatio float64 `json:"max_over_subscription_ratio"`
+	ReservedPercentage       float64 `json:"reserved_percentage"`
+}
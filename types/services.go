@@ -0,0 +1,37 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+// CinderService represents health metrics of cinder service running on host
+// State - 1 if service is up, 0 otherwise
+// Status - 1 if service is enabled, 0 otherwise
+// UpdatedSecondsAgo - seconds elapsed since last service heartbeat, -1 if service has never reported
+type CinderService struct {
+	State             int     `json:"state"`
+	Status            int     `json:"status"`
+	UpdatedSecondsAgo float64 `json:"updated_seconds_ago"`
+}
+
+// NewCinderService creates health metrics of cinder service based on its reported state and status
+func NewCinderService(state, status string, updatedSecondsAgo float64) CinderService {
+	service := CinderService{UpdatedSecondsAgo: updatedSecondsAgo}
+	if state == "up" {
+		service.State = 1
+	}
+	if status == "enabled" {
+		service.Status = 1
+	}
+	return service
+}
@@ -14,6 +14,12 @@ limitations under the License.
 
 package types
 
+// SnapshotStatuses lists snapshot statuses reported by Cinder
+var SnapshotStatuses = []string{
+	"creating", "available", "backing-up", "deleting", "error", "deleted", "unmanaging", "restoring",
+	"error_deleting",
+}
+
 // Snapshots represents cinder volumes snapshots metric
 // Count - total number of snapshots counted
 // Bytes - total number of bytes counted
@@ -21,3 +27,44 @@ type Snapshots struct {
 	Count uint `json:"count"`
 	Bytes int  `json:"bytes"`
 }
+
+// TenantSnapshots represents cinder volumes snapshots metrics of single tenant
+// Snapshots - totals for all tenant snapshots
+// Status - totals for tenant snapshots per snapshot status
+type TenantSnapshots struct {
+	Snapshots
+	Status map[string]Snapshots
+}
+
+// Add counts snapshot of given size in GB and status
+func (t *TenantSnapshots) Add(size int, status string) {
+	bytes := size * 1024 * 1024 * 1024
+
+	t.Count++
+	t.Bytes += bytes
+
+	if t.Status == nil {
+		t.Status = map[string]Snapshots{}
+	}
+	status = knownStatus(status, SnapshotStatuses)
+	byStatus := t.Status[status]
+	byStatus.Count++
+	byStatus.Bytes += bytes
+	t.Status[status] = byStatus
+}
+
+// Merge adds snapshots counted for other tenant
+func (t *TenantSnapshots) Merge(other TenantSnapshots) {
+	t.Count += other.Count
+	t.Bytes += other.Bytes
+
+	if t.Status == nil {
+		t.Status = map[string]Snapshots{}
+	}
+	for status, snapshots := range other.Status {
+		byStatus := t.Status[status]
+		byStatus.Count += snapshots.Count
+		byStatus.Bytes += snapshots.Bytes
+		t.Status[status] = byStatus
+	}
+}
@@ -0,0 +1,29 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+Copyright 2016 Intel Corporation
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package types
+
+// OtherStatus is reported as status of resources in status missing in list of known statuses
+// so that totals per status always add up to total count
+const OtherStatus = "other"
+
+// knownStatus returns given status if it is one of known statuses, OtherStatus otherwise
+func knownStatus(status string, known []string) string {
+	for _, s := range known {
+		if s == status {
+			return status
+		}
+	}
+	return OtherStatus
+}
@@ -19,3 +19,6 @@ type Tenant struct {
 	Name string `json:"name"`
 	ID   string
 }
+
+// OrphanedTenant is reported as tenant of resources owned by unknown or deleted tenants
+const OrphanedTenant = "_orphaned"
@@ -14,6 +14,13 @@ limitations under the License.
 
 package types
 
+// VolumeStatuses lists volume statuses reported by Cinder
+var VolumeStatuses = []string{
+	"creating", "available", "reserved", "attaching", "detaching", "in-use", "maintenance", "deleting",
+	"awaiting-transfer", "error", "error_deleting", "backing-up", "restoring-backup", "error_backing-up",
+	"error_restoring", "error_extending", "downloading", "uploading", "retyping", "extending",
+}
+
 // Volumes represents cinder volumes metric
 // Count - total number of volumes counted
 // Bytes - total number of bytes counted
@@ -21,3 +28,87 @@ type Volumes struct {
 	Count uint `json:"count"`
 	Bytes int  `json:"bytes"`
 }
+
+// NoVolumeType is reported as volume type for volumes created without one
+const NoVolumeType = "none"
+
+// TenantVolumes represents cinder volumes metrics of single tenant
+// Volumes - totals for all tenant volumes
+// Status - totals for tenant volumes per volume status
+// Types - totals for tenant volumes per volume type
+type TenantVolumes struct {
+	Volumes
+	Status map[string]Volumes
+	Types  map[string]Volumes
+}
+
+// Add counts volume of given size in GB, status and volume type
+// Volume type name is sanitized to be used in namespace
+func (t *TenantVolumes) Add(size int, status, volumeType string) {
+	bytes := size * 1024 * 1024 * 1024
+
+	t.Count++
+	t.Bytes += bytes
+
+	if t.Status == nil {
+		t.Status = map[string]Volumes{}
+	}
+	status = knownStatus(status, VolumeStatuses)
+	byStatus := t.Status[status]
+	byStatus.Count++
+	byStatus.Bytes += bytes
+	t.Status[status] = byStatus
+
+	if volumeType == "" {
+		volumeType = NoVolumeType
+	}
+	volumeType = SanitizeName(volumeType)
+	if t.Types == nil {
+		t.Types = map[string]Volumes{}
+	}
+	byType := t.Types[volumeType]
+	byType.Count++
+	byType.Bytes += bytes
+	t.Types[volumeType] = byType
+}
+
+// Merge adds volumes counted for other tenant
+func (t *TenantVolumes) Merge(other TenantVolumes) {
+	t.Count += other.Count
+	t.Bytes += other.Bytes
+
+	if t.Status == nil {
+		t.Status = map[string]Volumes{}
+	}
+	for status, volumes := range other.Status {
+		byStatus := t.Status[status]
+		byStatus.Count += volumes.Count
+		byStatus.Bytes += volumes.Bytes
+		t.Status[status] = byStatus
+	}
+
+	if t.Types == nil {
+		t.Types = map[string]Volumes{}
+	}
+	for volumeType, volumes := range other.Types {
+		byType := t.Types[volumeType]
+		byType.Count += volumes.Count
+		byType.Bytes += volumes.Bytes
+		t.Types[volumeType] = byType
+	}
+}
+
+// SumVolumeTypes aggregates volumes per volume type for all given tenants, volume type names are sanitized
+func SumVolumeTypes(tenants map[string]TenantVolumes) map[string]Volumes {
+	sum := map[string]Volumes{}
+	for _, tenant := range tenants {
+		for volumeType, volumes := range tenant.Types {
+			volumeType = SanitizeName(volumeType)
+			total := sum[volumeType]
+			total.Count += volumes.Count
+			total.Bytes += volumes.Bytes
+			sum[volumeType] = total
+		}
+	}
+	return sum
+}